@@ -0,0 +1,31 @@
+package fp
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// topLimbMask keeps the low Bits-192 bits of the most significant limb, so that
+// a candidate drawn from 32 random bytes is smaller than 2^Bits.
+const topLimbMask = (uint64(1) << (Bits - 192)) - 1
+
+// SetRandomFrom sets z to a uniformly random element, drawing the randomness from r.
+// Passing a seeded reader gives a reproducible sequence of elements.
+//
+// The element is sampled with rejection sampling: a candidate of Bits bits
+// is drawn and discarded if it is not smaller than the modulus.
+func (z *Element) SetRandomFrom(r io.Reader) error {
+	var buf [Bytes]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return err
+		}
+		z[0] = binary.BigEndian.Uint64(buf[24:32])
+		z[1] = binary.BigEndian.Uint64(buf[16:24])
+		z[2] = binary.BigEndian.Uint64(buf[8:16])
+		z[3] = binary.BigEndian.Uint64(buf[0:8]) & topLimbMask
+		if z.smallerThanModulus() {
+			return nil
+		}
+	}
+}
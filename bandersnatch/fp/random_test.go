@@ -0,0 +1,32 @@
+package fp
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestSetRandomFrom(t *testing.T) {
+	r1 := rand.New(rand.NewSource(7))
+	r2 := rand.New(rand.NewSource(7))
+	for i := 0; i < 64; i++ {
+		var a, b Element
+		if err := a.SetRandomFrom(r1); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.SetRandomFrom(r2); err != nil {
+			t.Fatal(err)
+		}
+		if !a.Equal(&b) {
+			t.Fatalf("element %d differs for the same seed", i)
+		}
+		if !a.smallerThanModulus() {
+			t.Fatalf("element %d is not reduced", i)
+		}
+	}
+
+	var z Element
+	if err := z.SetRandomFrom(bytes.NewReader(make([]byte, Bytes-1))); err == nil {
+		t.Fatal("expected an error when the reader runs out of bytes")
+	}
+}
@@ -0,0 +1,51 @@
+package fr
+
+import "testing"
+
+func TestBatchInvert(t *testing.T) {
+	a := make([]Element, 256)
+	for i := range a {
+		a[i].SetRandom()
+	}
+	// Zeroes are left as zero, and don't affect the rest of the inverses.
+	a[0].SetZero()
+	a[100].SetZero()
+	a[255].SetZero()
+
+	got := BatchInvert(a)
+	if len(got) != len(a) {
+		t.Fatalf("expected %d inverses, got %d", len(a), len(got))
+	}
+	for i := range a {
+		var expected Element
+		expected.Inverse(&a[i])
+		if !got[i].Equal(&expected) {
+			t.Fatalf("inverse %d is incorrect", i)
+		}
+	}
+
+	if len(BatchInvert(nil)) != 0 {
+		t.Fatal("inverting an empty slice should return an empty slice")
+	}
+}
+
+func BenchmarkBatchInvert(b *testing.B) {
+	a := make([]Element, 256)
+	for i := range a {
+		a[i].SetRandom()
+	}
+
+	b.Run("individual", func(b *testing.B) {
+		res := make([]Element, len(a))
+		for i := 0; i < b.N; i++ {
+			for j := range a {
+				res[j].Inverse(&a[j])
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = BatchInvert(a)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package fr
+
+import "runtime"
+
+// Side channels:
+//
+// On amd64, Add, Sub, Double, Mul, Square and FromMont are implemented in
+// assembly without data dependent branches. Neg branches on a zero input, the
+// generic versions used on other architectures branch on the final reduction,
+// and so does mulByConstant.
+// Equal, IsZero, Cmp, Inverse, Exp, Sqrt, Legendre, SetBigInt and the byte
+// conversions are not constant-time and must not be used with secret inputs
+// when timing matters; use EqualCT and Select instead.
+
+// EqualCT returns 1 if z == x and 0 otherwise, in constant time.
+func (z Element) EqualCT(x *Element) int {
+	d := (z[0] ^ x[0]) | (z[1] ^ x[1]) | (z[2] ^ x[2]) | (z[3] ^ x[3])
+	// (d | -d) has its top bit set if and only if d != 0.
+	return int(((d | -d) >> 63) ^ 1)
+}
+
+// Select sets z to a if cond == 1 and to b if cond == 0, in constant time, and returns z.
+// The behaviour is undefined if cond is neither 0 nor 1.
+func (z *Element) Select(cond int, a, b *Element) *Element {
+	mask := -uint64(cond)
+	z[0] = (a[0] & mask) | (b[0] &^ mask)
+	z[1] = (a[1] & mask) | (b[1] &^ mask)
+	z[2] = (a[2] & mask) | (b[2] &^ mask)
+	z[3] = (a[3] & mask) | (b[3] &^ mask)
+	return z
+}
+
+// Zeroize overwrites z with zeros, so that a secret scalar doesn't outlive its use in
+// memory. This is best-effort: Element is a value type, so copies made by assignments,
+// function calls or the garbage collector moving stacks are not cleared.
+func (z *Element) Zeroize() {
+	zeroize(z)
+}
+
+// ZeroizeSlice overwrites every element of s with zeros. See Element.Zeroize.
+func ZeroizeSlice(s []Element) {
+	for i := range s {
+		zeroize(&s[i])
+	}
+}
+
+// zeroize isn't inlined so that the stores can't be removed as dead by the caller,
+// and runtime.KeepAlive makes z live until they are done.
+//
+//go:noinline
+func zeroize(z *Element) {
+	for i := range z {
+		z[i] = 0
+	}
+	runtime.KeepAlive(z)
+}
@@ -0,0 +1,73 @@
+package fr
+
+import "testing"
+
+func TestEqualCT(t *testing.T) {
+	var a, b Element
+	a.SetRandom()
+	b.Set(&a)
+	if a.EqualCT(&b) != 1 {
+		t.Fatal("equal elements should return 1")
+	}
+
+	// Flipping any bit of any limb makes the elements different.
+	for limb := 0; limb < Limbs; limb++ {
+		for bit := 0; bit < 64; bit++ {
+			c := a
+			c[limb] ^= 1 << bit
+			if a.EqualCT(&c) != 0 {
+				t.Fatalf("elements differing in bit %d of limb %d should return 0", bit, limb)
+			}
+		}
+	}
+
+	zero := Zero()
+	if zero.EqualCT(&zero) != 1 {
+		t.Fatal("zero should be equal to itself")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	var a, b, z Element
+	a.SetRandom()
+	b.SetRandom()
+
+	z.Select(1, &a, &b)
+	if !z.Equal(&a) {
+		t.Fatal("cond == 1 should select a")
+	}
+	z.Select(0, &a, &b)
+	if !z.Equal(&b) {
+		t.Fatal("cond == 0 should select b")
+	}
+
+	// The receiver can alias the inputs.
+	z.Set(&a)
+	z.Select(0, &z, &b)
+	if !z.Equal(&b) {
+		t.Fatal("aliasing the receiver changed the result")
+	}
+}
+
+func TestZeroize(t *testing.T) {
+	var z Element
+	z.SetRandom()
+	if z.IsZero() {
+		t.Fatal("expected a non-zero element")
+	}
+	z.Zeroize()
+	if z != (Element{}) {
+		t.Fatal("element is not zero after Zeroize")
+	}
+
+	s := make([]Element, 5)
+	for i := range s {
+		s[i].SetRandom()
+	}
+	ZeroizeSlice(s)
+	for i := range s {
+		if s[i] != (Element{}) {
+			t.Fatalf("element %d is not zero after ZeroizeSlice", i)
+		}
+	}
+}
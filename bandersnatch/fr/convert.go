@@ -0,0 +1,72 @@
+package fr
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// SetInt64 sets z to v and returns z in Montgomery form.
+// Negative values are mapped to q - |v|.
+func (z *Element) SetInt64(v int64) *Element {
+	if v >= 0 {
+		return z.SetUint64(uint64(v))
+	}
+
+	// Note that uint64(-v) is the correct magnitude even for math.MinInt64
+	z.SetUint64(uint64(-v))
+	return z.Neg(z)
+}
+
+// BigInt sets dst to the canonical (non montgomery) value of z, which is in [0, q),
+// and returns dst. It is the inverse of SetBigInt for values in that range.
+func (z Element) BigInt(dst *big.Int) *big.Int {
+	return z.ToBigIntRegular(dst)
+}
+
+// twoTo64 is 2^64 in Montgomery form.
+var twoTo64 = func() Element {
+	var z Element
+	z.SetUint64(1 << 63)
+	return *z.Double(&z)
+}()
+
+// SetBytesWide interprets b as a big-endian unsigned integer of any length, sets z to
+// it reduced modulo q, and returns z. Unlike SetBytes it doesn't allocate.
+//
+// Reducing a uniformly random input gives a value whose distribution is within
+// 2^-(8*len(b)-253) of uniform, so inputs of 48 bytes or more, e.g. a 64 byte hash,
+// give scalars with negligible bias. A 32 byte input doesn't.
+func (z *Element) SetBytesWide(b []byte) *Element {
+	z.SetZero()
+
+	// Horner's rule over 64 bit words, starting from the most significant one,
+	// which is shorter when len(b) is not a multiple of 8.
+	var word [8]byte
+	head := len(b) % 8
+	if head != 0 {
+		copy(word[8-head:], b[:head])
+		z.SetUint64(binary.BigEndian.Uint64(word[:]))
+	}
+	for i := head; i < len(b); i += 8 {
+		var limb Element
+		limb.SetUint64(binary.BigEndian.Uint64(b[i : i+8]))
+		z.Mul(z, &twoTo64)
+		z.Add(z, &limb)
+	}
+
+	return z
+}
+
+// IsCanonical returns true if the internal representation of z is reduced, ie smaller
+// than q. Every operation of this package returns reduced elements, so this only fails for
+// elements built by setting the limbs directly, e.g. with an unsafe cast from bytes.
+// Commitments and MSMs assume reduced inputs and don't check them, for speed.
+func (z *Element) IsCanonical() bool {
+	var borrow uint64
+	_, borrow = bits.Sub64(z[0], qElement[0], 0)
+	_, borrow = bits.Sub64(z[1], qElement[1], borrow)
+	_, borrow = bits.Sub64(z[2], qElement[2], borrow)
+	_, borrow = bits.Sub64(z[3], qElement[3], borrow)
+	return borrow == 1
+}
@@ -0,0 +1,133 @@
+package fr
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestSetInt64(t *testing.T) {
+	values := []int64{0, 1, -1, 42, -42, math.MaxInt64, math.MinInt64}
+	for _, v := range values {
+		var got, expected Element
+		got.SetInt64(v)
+		expected.SetBigInt(big.NewInt(v))
+
+		if !got.Equal(&expected) {
+			t.Fatalf("SetInt64(%d) = %s, expected %s", v, got.String(), expected.String())
+		}
+	}
+
+	var minusOne Element
+	minusOne.SetInt64(-1)
+	expected := MinusOne()
+	if !minusOne.Equal(&expected) {
+		t.Fatal("SetInt64(-1) should be q-1")
+	}
+}
+
+func TestBigIntRoundTrip(t *testing.T) {
+	q := Modulus()
+
+	var qPlusFive, minusFive, twoQMinusOne big.Int
+	qPlusFive.Add(q, big.NewInt(5))
+	minusFive.SetInt64(-5)
+	twoQMinusOne.Lsh(q, 1).Sub(&twoQMinusOne, big.NewInt(1))
+
+	tests := []struct {
+		in       *big.Int
+		expected *big.Int
+	}{
+		{big.NewInt(0), big.NewInt(0)},
+		{big.NewInt(12345), big.NewInt(12345)},
+		{q, big.NewInt(0)},
+		{&qPlusFive, big.NewInt(5)},
+		{&minusFive, new(big.Int).Sub(q, big.NewInt(5))},
+		{&twoQMinusOne, new(big.Int).Sub(q, big.NewInt(1))},
+	}
+	for _, test := range tests {
+		var z Element
+		z.SetBigInt(test.in)
+
+		var got big.Int
+		if z.BigInt(&got).Cmp(test.expected) != 0 {
+			t.Fatalf("SetBigInt(%s).BigInt() = %s, expected %s", test.in, &got, test.expected)
+		}
+	}
+
+	// The input is not modified when it is reduced.
+	if minusFive.Int64() != -5 {
+		t.Fatal("SetBigInt modified its input")
+	}
+}
+
+func TestSetBytesWide(t *testing.T) {
+	q := Modulus()
+	for _, length := range []int{0, 1, 7, 8, 31, 32, 33, 48, 64, 100} {
+		for i := 0; i < 10; i++ {
+			b := make([]byte, length)
+			if _, err := rand.Read(b); err != nil {
+				t.Fatal(err)
+			}
+
+			var got Element
+			got.SetBytesWide(b)
+
+			var expected big.Int
+			expected.SetBytes(b).Mod(&expected, q)
+			var gotBig big.Int
+			if got.BigInt(&gotBig).Cmp(&expected) != 0 {
+				t.Fatalf("length %d: got %s, expected %s", length, &gotBig, &expected)
+			}
+
+			if length == 32 {
+				var fromSetBytes Element
+				fromSetBytes.SetBytes(b)
+				if !got.Equal(&fromSetBytes) {
+					t.Fatal("SetBytesWide should match SetBytes for 32 byte inputs")
+				}
+			}
+		}
+	}
+
+	// Reducing 64 random bytes should give a value in the lower half of [0, q)
+	// about half of the time. The bound is more than 6 standard deviations.
+	const samples = 10000
+	halfQ := new(big.Int).Rsh(q, 1)
+	var lowerHalf int
+	b := make([]byte, 64)
+	for i := 0; i < samples; i++ {
+		if _, err := rand.Read(b); err != nil {
+			t.Fatal(err)
+		}
+		var z Element
+		var zBig big.Int
+		if z.SetBytesWide(b).BigInt(&zBig).Cmp(halfQ) <= 0 {
+			lowerHalf++
+		}
+	}
+	if lowerHalf < samples/2-300 || lowerHalf > samples/2+300 {
+		t.Fatalf("%d of %d reduced values are in the lower half", lowerHalf, samples)
+	}
+}
+
+func TestIsCanonical(t *testing.T) {
+	one, minusOne := One(), MinusOne()
+	var random Element
+	random.SetRandom()
+	for _, z := range []Element{{}, one, minusOne, random} {
+		if !z.IsCanonical() {
+			t.Fatalf("%s should be canonical", z.DebugString())
+		}
+	}
+
+	qPlusOne := qElement
+	qPlusOne[0]++
+	allOnes := Element{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}
+	for _, z := range []Element{qElement, qPlusOne, allOnes} {
+		if z.IsCanonical() {
+			t.Fatalf("%s should not be canonical", z.DebugString())
+		}
+	}
+}
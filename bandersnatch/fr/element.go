@@ -604,6 +604,7 @@ func (z *Element) BitLen() int {
 }
 
 // Exp z = x^exponent mod q
+// The sign of exponent is ignored, use ExpInt for negative exponents.
 func (z *Element) Exp(x Element, exponent *big.Int) *Element {
 	var bZero big.Int
 	if exponent.Cmp(&bZero) == 0 {
@@ -0,0 +1,14 @@
+package fr
+
+import "math/big"
+
+// ExpInt sets z to x^exponent mod q and returns z. Unlike Exp, which is generated code
+// and only uses the absolute value of the exponent, a negative exponent is applied to
+// the inverse of x, ie x^-k = (x^-1)^k. The exponent is not modified.
+func (z *Element) ExpInt(x Element, exponent *big.Int) *Element {
+	if exponent.Sign() < 0 {
+		x.Inverse(&x)
+		return z.Exp(x, new(big.Int).Neg(exponent))
+	}
+	return z.Exp(x, exponent)
+}
@@ -0,0 +1,36 @@
+package fr
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestExpInt(t *testing.T) {
+	var x Element
+	x.SetRandom()
+	var xBig big.Int
+	x.ToBigIntRegular(&xBig)
+	q := Modulus()
+
+	for _, k := range []int64{0, 1, 2, 7, 1 << 40, -1, -2, -7, -1 << 40} {
+		exponent := big.NewInt(k)
+		var got Element
+		got.ExpInt(x, exponent)
+		if exponent.Int64() != k {
+			t.Fatal("ExpInt modified the exponent")
+		}
+
+		// A negative exponent is applied to the modular inverse.
+		base := &xBig
+		if k < 0 {
+			base = new(big.Int).ModInverse(&xBig, q)
+		}
+		var expectedBig big.Int
+		expectedBig.Exp(base, new(big.Int).Abs(exponent), q)
+		var expected Element
+		expected.SetBigInt(&expectedBig)
+		if !got.Equal(&expected) {
+			t.Fatalf("x^%d is incorrect", k)
+		}
+	}
+}
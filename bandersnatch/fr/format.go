@@ -0,0 +1,31 @@
+package fr
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// DebugString returns the canonical decimal value of z followed by the four
+// Montgomery limbs as they are stored, least significant first. It is meant for
+// inspecting internals when debugging, not for serialization.
+func (z Element) DebugString() string {
+	var b big.Int
+	return fmt.Sprintf("%s (mont limbs: [%#016x %#016x %#016x %#016x])",
+		z.BigInt(&b).String(), z[0], z[1], z[2], z[3])
+}
+
+// Format implements fmt.Formatter. %x and %X print the canonical value in big-endian
+// hex, and %d, %v and %s print it in decimal. Unlike String, which shows small negative
+// values as such, q-1 prints as q-1 rather than -1.
+func (z Element) Format(s fmt.State, verb rune) {
+	var b big.Int
+	z.BigInt(&b)
+	switch verb {
+	case 'd', 'v', 's':
+		b.Format(s, 'd')
+	case 'x', 'X':
+		b.Format(s, verb)
+	default:
+		fmt.Fprintf(s, "%%!%c(fr.Element=%s)", verb, b.String())
+	}
+}
@@ -0,0 +1,43 @@
+package fr
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	var z Element
+	z.SetUint64(0xabcdef)
+	minusOne := MinusOne()
+	minusOneDecimal := new(big.Int).Sub(Modulus(), big.NewInt(1)).String()
+
+	tests := []struct {
+		format   string
+		value    interface{}
+		expected string
+	}{
+		{"%v", z, "11259375"},
+		{"%s", &z, "11259375"},
+		{"%d", z, "11259375"},
+		{"%x", z, "abcdef"},
+		{"%#X", &z, "0XABCDEF"},
+		{"%v", minusOne, minusOneDecimal},
+		{"%s", minusOne, minusOneDecimal},
+		{"%x", minusOne, "1cfb69d4ca675f520cce760202687600ff8f87007419047174fd06b52876e7e0"},
+		{"%q", z, "%!q(fr.Element=11259375)"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprintf(test.format, test.value); got != test.expected {
+			t.Fatalf("format %s: expected %s, got %s", test.format, test.expected, got)
+		}
+	}
+}
+
+func TestDebugString(t *testing.T) {
+	one := One()
+	expected := fmt.Sprintf("1 (mont limbs: [%#016x %#016x %#016x %#016x])", one[0], one[1], one[2], one[3])
+	if got := one.DebugString(); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
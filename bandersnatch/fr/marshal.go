@@ -0,0 +1,59 @@
+package fr
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// MarshalText implements encoding.TextMarshaler. The element is encoded as
+// its canonical (non montgomery) big-endian value in 0x-prefixed hex.
+func (z Element) MarshalText() ([]byte, error) {
+	b := z.Bytes()
+	return []byte("0x" + hex.EncodeToString(b[:])), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// See MarshalText for the expected encoding; values not smaller than
+// the modulus are rejected instead of being reduced.
+func (z *Element) UnmarshalText(text []byte) error {
+	s := string(text)
+	if !strings.HasPrefix(s, "0x") {
+		return errors.New("missing 0x prefix")
+	}
+	b, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return fmt.Errorf("decoding hex: %s", err)
+	}
+	if len(b) != Bytes {
+		return fmt.Errorf("got %d bytes, expected %d", len(b), Bytes)
+	}
+	if new(big.Int).SetBytes(b).Cmp(&_modulus) >= 0 {
+		return errors.New("value is not smaller than the modulus")
+	}
+	z.SetBytes(b)
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the element as a string.
+// See MarshalText for the format.
+func (z Element) MarshalJSON() ([]byte, error) {
+	text, err := z.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (z *Element) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return z.UnmarshalText([]byte(s))
+}
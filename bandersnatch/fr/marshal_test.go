@@ -0,0 +1,61 @@
+package fr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestElementJSONRoundTrip(t *testing.T) {
+	type fixture struct {
+		Value Element
+		Ptr   *Element
+	}
+	var a, b Element
+	a.SetRandom()
+	b.SetRandom()
+
+	data, err := json.Marshal(fixture{Value: a, Ptr: &b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got fixture
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Value.Equal(&a) || !got.Ptr.Equal(&b) {
+		t.Fatal("elements changed after a JSON round trip")
+	}
+}
+
+func TestElementMarshalText(t *testing.T) {
+	one := One()
+	text, err := one.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "0x" + strings.Repeat("0", 63) + "1"
+	if string(text) != expected {
+		t.Fatalf("expected %s, got %s", expected, text)
+	}
+
+	modulusHex := "0x" + Modulus().Text(16)
+	invalid := []string{
+		"",
+		strings.TrimPrefix(expected, "0x"),
+		expected[:len(expected)-1],
+		expected + "00",
+		"0x" + strings.Repeat("zz", Bytes),
+		modulusHex,
+	}
+	for _, s := range invalid {
+		var z Element
+		if err := z.UnmarshalText([]byte(s)); err == nil {
+			t.Fatalf("expected an error for %q", s)
+		}
+	}
+	var z Element
+	if err := json.Unmarshal([]byte("42"), &z); err == nil {
+		t.Fatal("expected an error for a JSON number")
+	}
+}
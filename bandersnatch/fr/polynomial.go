@@ -0,0 +1,30 @@
+package fr
+
+import "github.com/crate-crypto/go-ipa/common/parallel"
+
+// EvalPolynomial evaluates the polynomial with the given coefficients at a point,
+// using Horner's method. coeffs[i] is the coefficient of X^i, and a polynomial
+// with no coefficients evaluates to zero.
+func EvalPolynomial(coeffs []Element, at Element) Element {
+	var result Element
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		result.Mul(&result, &at)
+		result.Add(&result, &coeffs[i])
+	}
+	return result
+}
+
+// EvalPolynomialBatch evaluates the polynomial with the given coefficients at every point.
+// The evaluations are split across goroutines.
+func EvalPolynomialBatch(coeffs []Element, points []Element) []Element {
+	result := make([]Element, len(points))
+	if len(points) == 0 {
+		return result
+	}
+	parallel.Execute(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			result[i] = EvalPolynomial(coeffs, points[i])
+		}
+	})
+	return result
+}
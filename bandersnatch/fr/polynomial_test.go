@@ -0,0 +1,72 @@
+package fr
+
+import "testing"
+
+// naiveEval evaluates sum(coeffs[i] * at^i).
+func naiveEval(coeffs []Element, at Element) Element {
+	return InnerProduct(coeffs, Powers(at, len(coeffs)))
+}
+
+func TestEvalPolynomial(t *testing.T) {
+	coeffs := make([]Element, 17)
+	for i := range coeffs {
+		coeffs[i].SetRandom()
+	}
+	var at Element
+	at.SetRandom()
+
+	got := EvalPolynomial(coeffs, at)
+	expected := naiveEval(coeffs, at)
+	if !got.Equal(&expected) {
+		t.Fatal("evaluation doesn't match the naive one")
+	}
+
+	// 1 + 2X + 3X^2 at X=2 is 17.
+	small := make([]Element, 3)
+	for i := range small {
+		small[i].SetUint64(uint64(i + 1))
+	}
+	var two, seventeen Element
+	two.SetUint64(2)
+	seventeen.SetUint64(17)
+	got = EvalPolynomial(small, two)
+	if !got.Equal(&seventeen) {
+		t.Fatalf("expected 17, got %s", got.String())
+	}
+
+	got = EvalPolynomial(nil, at)
+	if !got.IsZero() {
+		t.Fatal("the empty polynomial should evaluate to zero")
+	}
+}
+
+func TestEvalPolynomialBatch(t *testing.T) {
+	coeffs := make([]Element, 9)
+	for i := range coeffs {
+		coeffs[i].SetRandom()
+	}
+	points := make([]Element, 33)
+	for i := range points {
+		points[i].SetRandom()
+	}
+
+	got := EvalPolynomialBatch(coeffs, points)
+	if len(got) != len(points) {
+		t.Fatalf("expected %d evaluations, got %d", len(points), len(got))
+	}
+	for i := range points {
+		expected := naiveEval(coeffs, points[i])
+		if !got[i].Equal(&expected) {
+			t.Fatalf("evaluation at point %d is incorrect", i)
+		}
+	}
+
+	if len(EvalPolynomialBatch(coeffs, nil)) != 0 {
+		t.Fatal("no points should give no evaluations")
+	}
+	for _, e := range EvalPolynomialBatch(nil, points) {
+		if !e.IsZero() {
+			t.Fatal("the empty polynomial should evaluate to zero")
+		}
+	}
+}
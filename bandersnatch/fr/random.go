@@ -0,0 +1,77 @@
+package fr
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+)
+
+// topLimbMask keeps the low Bits-192 bits of the most significant limb, so that
+// a candidate drawn from 32 random bytes is smaller than 2^Bits.
+const topLimbMask = (uint64(1) << (Bits - 192)) - 1
+
+// RandomVector returns n random elements read from crypto/rand.
+// The entropy for all of the elements is read in a single call, which is
+// faster than calling SetRandom n times.
+func RandomVector(n int) ([]Element, error) {
+	return RandomVectorFrom(rand.Reader, n)
+}
+
+// RandomVectorFrom returns n random elements, drawing the randomness from r.
+// Passing a seeded reader gives a reproducible sequence of elements.
+//
+// Every element is sampled with rejection sampling: a candidate of Bits bits
+// is drawn and discarded if it is not smaller than the modulus, so the
+// output is uniformly distributed.
+func RandomVectorFrom(r io.Reader, n int) ([]Element, error) {
+	res := make([]Element, n)
+
+	filled := 0
+	for filled < n {
+		// Read enough bytes for the elements that are still missing;
+		// around 10% of the candidates are rejected.
+		buf := make([]byte, Bytes*(n-filled))
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		for offset := 0; offset < len(buf); offset += Bytes {
+			if candidateFromBytes(&res[filled], buf[offset:offset+Bytes]) {
+				filled++
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// SetRandomFrom sets z to a uniformly random element, drawing the randomness from r.
+// Passing a seeded reader gives a reproducible sequence of elements.
+// See RandomVectorFrom for how the element is sampled.
+func (z *Element) SetRandomFrom(r io.Reader) error {
+	var buf [Bytes]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return err
+		}
+		if candidateFromBytes(z, buf[:]) {
+			return nil
+		}
+	}
+}
+
+// candidateFromBytes sets z from 32 big-endian bytes, keeping only the low Bits bits.
+// It returns false if the candidate is not smaller than the modulus.
+func candidateFromBytes(z *Element, b []byte) bool {
+	z[0] = binary.BigEndian.Uint64(b[24:32])
+	z[1] = binary.BigEndian.Uint64(b[16:24])
+	z[2] = binary.BigEndian.Uint64(b[8:16])
+	z[3] = binary.BigEndian.Uint64(b[0:8]) & topLimbMask
+
+	return z.smallerThanModulus()
+}
+
+// smallerThanModulus returns true if z < q
+func (z *Element) smallerThanModulus() bool {
+	return (z[3] < qElement[3] || (z[3] == qElement[3] && (z[2] < qElement[2] || (z[2] == qElement[2] && (z[1] < qElement[1] || (z[1] == qElement[1] && (z[0] < qElement[0])))))))
+}
@@ -0,0 +1,104 @@
+package fr
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestRandomVector(t *testing.T) {
+	const n = 4096
+	vector, err := RandomVector(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vector) != n {
+		t.Fatalf("expected %d elements, got %d", n, len(vector))
+	}
+
+	largest := 0
+	for i := range vector {
+		if vector[i].biggerOrEqualModulus() {
+			t.Fatalf("element %d is not reduced", i)
+		}
+		if vector[i].LexicographicallyLargest() {
+			largest++
+		}
+	}
+	// About half of the elements should be larger than their negation.
+	if largest < n*45/100 || largest > n*55/100 {
+		t.Fatalf("elements don't look uniformly distributed: %d out of %d are in the upper half", largest, n)
+	}
+}
+
+func TestRandomVectorFromIsDeterministic(t *testing.T) {
+	a, err := RandomVectorFrom(rand.New(rand.NewSource(42)), 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := RandomVectorFrom(rand.New(rand.NewSource(42)), 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range a {
+		if !a[i].Equal(&b[i]) {
+			t.Fatalf("element %d differs for the same seed", i)
+		}
+	}
+
+	c, err := RandomVectorFrom(rand.New(rand.NewSource(43)), 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a[0].Equal(&c[0]) {
+		t.Fatal("different seeds should give different elements")
+	}
+}
+
+func TestSetRandomFrom(t *testing.T) {
+	r1 := rand.New(rand.NewSource(7))
+	r2 := rand.New(rand.NewSource(7))
+	for i := 0; i < 64; i++ {
+		var a, b Element
+		if err := a.SetRandomFrom(r1); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.SetRandomFrom(r2); err != nil {
+			t.Fatal(err)
+		}
+		if !a.Equal(&b) {
+			t.Fatalf("element %d differs for the same seed", i)
+		}
+		if a.biggerOrEqualModulus() {
+			t.Fatalf("element %d is not reduced", i)
+		}
+	}
+
+	var z Element
+	if err := z.SetRandomFrom(bytes.NewReader(make([]byte, Bytes-1))); err == nil {
+		t.Fatal("expected an error when the reader runs out of bytes")
+	}
+}
+
+func BenchmarkRandomVector(b *testing.B) {
+	const n = 256
+
+	b.Run("RandomVector", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := RandomVector(n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("SetRandom loop", func(b *testing.B) {
+		vector := make([]Element, n)
+		for i := 0; i < b.N; i++ {
+			for j := range vector {
+				if _, err := vector[j].SetRandom(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package fr
+
+import "github.com/crate-crypto/go-ipa/common/parallel"
+
+// minParallelBatchSize is the slice length from which batch conversions are
+// split across goroutines; below it the goroutine overhead isn't worth it.
+const minParallelBatchSize = 1024
+
+// Powers returns the first n powers of x, ie [1, x, x^2, ..., x^(n-1)].
+// It uses n-1 multiplications, and returns an empty slice if n is zero.
+func Powers(x Element, n int) []Element {
+	result := make([]Element, n)
+	if n == 0 {
+		return result
+	}
+	result[0].SetOne()
+
+	for i := 1; i < n; i++ {
+		result[i].Mul(&result[i-1], &x)
+	}
+
+	return result
+}
+
+// InnerProduct returns the inner product of a and b, ie sum(a[i] * b[i]).
+// panics if len(a) != len(b)
+func InnerProduct(a, b []Element) Element {
+	if len(a) != len(b) {
+		panic("two vectors must have the same lengths")
+	}
+
+	var result Element
+	for i := 0; i < len(a); i++ {
+		var tmp Element
+		tmp.Mul(&a[i], &b[i])
+		result.Add(&result, &tmp)
+	}
+
+	return result
+}
+
+// BatchFromMont converts every element of a in place from Montgomery to regular form.
+func BatchFromMont(a []Element) {
+	batchApply(a, func(z *Element) { z.FromMont() })
+}
+
+// BatchToMont converts every element of a in place from regular to Montgomery form.
+func BatchToMont(a []Element) {
+	batchApply(a, func(z *Element) { z.ToMont() })
+}
+
+func batchApply(a []Element, f func(z *Element)) {
+	if len(a) < minParallelBatchSize {
+		for i := range a {
+			f(&a[i])
+		}
+		return
+	}
+	parallel.Execute(len(a), func(start, end int) {
+		for i := start; i < end; i++ {
+			f(&a[i])
+		}
+	})
+}
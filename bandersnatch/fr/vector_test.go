@@ -0,0 +1,116 @@
+package fr
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPowers(t *testing.T) {
+	var x Element
+	x.SetRandom()
+
+	powers := Powers(x, 10)
+	if len(powers) != 10 {
+		t.Fatalf("expected 10 powers, got %d", len(powers))
+	}
+	for i := range powers {
+		var expected Element
+		expected.Exp(x, big.NewInt(int64(i)))
+		if !powers[i].Equal(&expected) {
+			t.Fatalf("power %d is incorrect", i)
+		}
+	}
+
+	if len(Powers(x, 0)) != 0 {
+		t.Fatal("zero powers should return an empty slice")
+	}
+	one := One()
+	if first := Powers(x, 1); len(first) != 1 || !first[0].Equal(&one) {
+		t.Fatal("the first power should be one")
+	}
+}
+
+func TestInnerProduct(t *testing.T) {
+	a := make([]Element, 5)
+	b := make([]Element, 5)
+	for i := range a {
+		a[i].SetUint64(uint64(i + 1))
+		b[i].SetUint64(uint64(10 * (i + 1)))
+	}
+
+	// 10 + 40 + 90 + 160 + 250
+	var expected Element
+	expected.SetUint64(550)
+	got := InnerProduct(a, b)
+	if !got.Equal(&expected) {
+		t.Fatalf("expected %s, got %s", expected.String(), got.String())
+	}
+
+	empty := InnerProduct(nil, nil)
+	if !empty.IsZero() {
+		t.Fatal("the inner product of empty vectors should be zero")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected a panic for vectors of different lengths")
+		}
+	}()
+	InnerProduct(a, b[:4])
+}
+
+func BenchmarkPowers(b *testing.B) {
+	var x Element
+	x.SetRandom()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Powers(x, 256)
+	}
+}
+
+func TestBatchMontConversions(t *testing.T) {
+	// Below and above the size from which the work is parallelized.
+	for _, n := range []int{0, 7, minParallelBatchSize + 3} {
+		a := make([]Element, n)
+		for i := range a {
+			a[i].SetRandom()
+		}
+		original := append([]Element{}, a...)
+
+		BatchFromMont(a)
+		for i := range a {
+			expected := original[i].ToRegular()
+			if !a[i].Equal(&expected) {
+				t.Fatalf("n=%d: element %d was not converted from Montgomery form", n, i)
+			}
+		}
+
+		BatchToMont(a)
+		for i := range a {
+			if !a[i].Equal(&original[i]) {
+				t.Fatalf("n=%d: element %d didn't round trip", n, i)
+			}
+		}
+	}
+}
+
+func BenchmarkBatchFromMont(b *testing.B) {
+	a := make([]Element, 4096)
+	for i := range a {
+		a[i].SetRandom()
+	}
+
+	b.Run("loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range a {
+				a[j].FromMont()
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			BatchFromMont(a)
+		}
+	})
+}
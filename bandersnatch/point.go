@@ -18,12 +18,17 @@
 package bandersnatch
 
 import (
+	"bytes"
 	"crypto/subtle"
+	"errors"
+	"fmt"
 
 	"io"
+	"math/bits"
 
 	"github.com/crate-crypto/go-ipa/bandersnatch/fp"
 	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
+	"github.com/crate-crypto/go-ipa/common/parallel"
 )
 
 // PointAffine point on a twisted Edwards curve
@@ -148,6 +153,40 @@ func (p *PointAffine) WriteUncompressedPoint(w io.Writer) (int, error) {
 }
 
 
+// MarshalUncompressed returns the big endian encodings of x and y, in that order.
+// It is the same format as WriteUncompressedPoint.
+func (p *PointAffine) MarshalUncompressed() [2 * sizePointCompressed]byte {
+	var res [2 * sizePointCompressed]byte
+	x_bytes := p.X.Bytes()
+	y_bytes := p.Y.Bytes()
+	copy(res[:sizePointCompressed], x_bytes[:])
+	copy(res[sizePointCompressed:], y_bytes[:])
+	return res
+}
+
+// UnmarshalUncompressed sets p from an encoding produced by MarshalUncompressed.
+// Unlike SetBytes, it doesn't need to compute a square root, so it is much faster.
+// It checks that both coordinates are reduced and that the point is on the curve, but
+// not that it is in the prime subgroup.
+func (p *PointAffine) UnmarshalUncompressed(buf [2 * sizePointCompressed]byte) error {
+	var x, y fp.Element
+	x.SetBytes(buf[:sizePointCompressed])
+	y.SetBytes(buf[sizePointCompressed:])
+
+	x_bytes := x.Bytes()
+	y_bytes := y.Bytes()
+	if !bytes.Equal(x_bytes[:], buf[:sizePointCompressed]) || !bytes.Equal(y_bytes[:], buf[sizePointCompressed:]) {
+		return errors.New("coordinates are not reduced")
+	}
+
+	point := PointAffine{X: x, Y: y}
+	if !point.IsOnCurve() {
+		return errors.New("point is not on the curve")
+	}
+	p.Set(&point)
+	return nil
+}
+
 // Unmarshal alias to SetBytes()
 func (p *PointAffine) Unmarshal(b []byte) error {
 	_, err := p.SetBytes(b)
@@ -178,6 +217,13 @@ func (p *PointAffine) Identity() *PointAffine {
 	return p
 }
 
+// AffineIdentity returns the identity point (0, 1) in affine co-ordinates
+func AffineIdentity() PointAffine {
+	var p PointAffine
+	p.Identity()
+	return p
+}
+
 // Set sets p to be the identity point
 func (p *PointProj) Identity() *PointProj {
 
@@ -261,8 +307,25 @@ func (p *PointAffine) Sub(p1, p2 *PointAffine) *PointAffine {
 
 // Double doubles point (x,y) on a twisted Edwards curve with parameters a, d
 // modifies p
+//
+// Uses the dedicated doubling formula, which is cheaper than Add(p1, p1):
+// x3 = 2xy / (ax^2 + y^2), y3 = (y^2 - ax^2) / (2 - ax^2 - y^2)
 func (p *PointAffine) Double(p1 *PointAffine) *PointAffine {
-	p.Add(p1, p1)
+	var xy, axx, yy, numx, numy, denx, deny, two fp.Element
+	xy.Mul(&p1.X, &p1.Y)
+	axx.Square(&p1.X).Mul(&axx, &edwards.A)
+	yy.Square(&p1.Y)
+
+	numx.Double(&xy)
+	numy.Sub(&yy, &axx)
+
+	denx.Add(&axx, &yy)
+	two.SetUint64(2)
+	deny.Sub(&two, &denx)
+
+	p.X.Div(&numx, &denx)
+	p.Y.Div(&numy, &deny)
+
 	return p
 }
 
@@ -292,6 +355,80 @@ func (p *PointAffine) FromProj(p1 *PointProj) *PointAffine {
 	return p
 }
 
+// BatchProjToAffine converts many points from projective to affine coordinates
+// using a single field inversion (Montgomery's batch inversion trick).
+// A point with Z = 0 is not a valid projective point; it is mapped to the affine identity.
+func BatchProjToAffine(points []PointProj) []PointAffine {
+	zs := make([]fp.Element, len(points))
+	for i := range points {
+		zs[i] = points[i].Z
+	}
+	// BatchInvert leaves zeroes as zero.
+	zInvs := fp.BatchInvert(zs)
+
+	result := make([]PointAffine, len(points))
+	for i := range points {
+		if zInvs[i].IsZero() {
+			result[i].Identity()
+			continue
+		}
+		result[i].X.Mul(&points[i].X, &zInvs[i])
+		result[i].Y.Mul(&points[i].Y, &zInvs[i])
+	}
+	return result
+}
+
+// BatchAdd returns the sum of all the points, or the identity if there are none.
+func BatchAdd(points []PointProj) PointProj {
+	var result PointProj
+	result.Identity()
+	for i := range points {
+		result.Add(&result, &points[i])
+	}
+	return result
+}
+
+// BatchMixedAdd returns base plus the sum of all the affine points.
+// Mixed addition on twisted Edwards curves needs no inversion, so every point is
+// added to the accumulator directly; there is nothing to gain from batching inversions.
+func BatchMixedAdd(base PointProj, points []PointAffine) PointProj {
+	result := base
+	for i := range points {
+		result.MixedAdd(&result, &points[i])
+	}
+	return result
+}
+
+// BatchScalarMul returns scalars[i] * points[i] for every i, computed in parallel.
+// Unlike MultiExp, the products are not added up.
+// panics if len(points) != len(scalars)
+func BatchScalarMul(points []PointAffine, scalars []fr.Element) []PointProj {
+	if len(points) != len(scalars) {
+		panic(fmt.Sprintf("got %d points and %d scalars", len(points), len(scalars)))
+	}
+
+	result := make([]PointProj, len(points))
+	if len(points) == 0 {
+		return result
+	}
+	parallel.Execute(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			var point PointProj
+			point.FromAffine(&points[i])
+			result[i].ScalarMulWindowed(&point, &scalars[i])
+		}
+	})
+
+	return result
+}
+
+// BatchScalarMulAffine is like BatchScalarMul, but converts the products to affine
+// coordinates with a single inversion.
+// panics if len(points) != len(scalars)
+func BatchScalarMulAffine(points []PointAffine, scalars []fr.Element) []PointAffine {
+	return BatchProjToAffine(BatchScalarMul(points, scalars))
+}
+
 // FromAffine sets p in projective from p in affine
 func (p *PointProj) FromAffine(p1 *PointAffine) *PointProj {
 	p.X.Set(&p1.X)
@@ -430,6 +567,86 @@ func (p *PointProj) ScalarMul(p1 *PointProj, scalar_mont *fr.Element) *PointProj
 	return p
 }
 
+// ScalarMulWindowed sets p to scalar_mont * p1 and returns p. It computes the same result
+// as ScalarMul, but uses a signed window of width 4 (w-NAF): the scalar is recoded into
+// digits that are either zero or odd and in [-7, 7], so only P, 3P, 5P and 7P are
+// precomputed and at most one in four doublings is followed by an addition.
+func (p *PointProj) ScalarMulWindowed(p1 *PointProj, scalar_mont *fr.Element) *PointProj {
+	const width = 4
+
+	// table[i] = (2i+1) * p1
+	var table [1 << (width - 2)]PointProj
+	var double PointProj
+	table[0].Set(p1)
+	double.Double(p1)
+	for i := 1; i < len(table); i++ {
+		table[i].Add(&table[i-1], &double)
+	}
+
+	digits := wnaf(scalar_mont.ToRegular(), width)
+
+	var res, neg PointProj
+	res.Identity()
+	for i := len(digits) - 1; i >= 0; i-- {
+		res.Double(&res)
+		if d := digits[i]; d > 0 {
+			res.Add(&res, &table[d>>1])
+		} else if d < 0 {
+			neg.Neg(&table[(-d)>>1])
+			res.Add(&res, &neg)
+		}
+	}
+
+	p.Set(&res)
+	return p
+}
+
+// wnaf returns the width-w non-adjacent form of the scalar (in regular form), least
+// significant digit first. Every non-zero digit is odd and smaller than 2^(w-1) in
+// absolute value.
+func wnaf(scalar fr.Element, w uint) []int8 {
+	// One extra limb, since recoding can carry past the top bit of the scalar.
+	var k [fr.Limbs + 1]uint64
+	copy(k[:], scalar[:])
+
+	isZero := func() bool {
+		for _, limb := range k {
+			if limb != 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	digits := make([]int8, 0, fr.Bits+1)
+	for !isZero() {
+		var d int8
+		if k[0]&1 == 1 {
+			d = int8(k[0] & (1<<w - 1))
+			if d >= 1<<(w-1) {
+				d -= 1 << w
+			}
+			if d > 0 {
+				// The low bits of k are d, so there is no borrow.
+				k[0] -= uint64(d)
+			} else {
+				var carry uint64
+				k[0], carry = bits.Add64(k[0], uint64(-d), 0)
+				for i := 1; i < len(k) && carry != 0; i++ {
+					k[i], carry = bits.Add64(k[i], 0, carry)
+				}
+			}
+		}
+		digits = append(digits, d)
+
+		for i := 0; i < len(k)-1; i++ {
+			k[i] = k[i]>>1 | k[i+1]<<63
+		}
+		k[len(k)-1] >>= 1
+	}
+	return digits
+}
+
 // All points in the prime subgroup have prime order
 // so we can check for prime order by multiplying by the order
 func (p PointAffine) IsInPrimeSubgroup() bool {
@@ -458,6 +675,10 @@ func (p PointAffine) IsInPrimeSubgroup() bool {
 	return identity.Equal(&tmp)
 }
 
+// GetPointFromX returns the point with the given x co-ordinate.
+// Out of the two candidate y co-ordinates, it returns the lexicographically
+// largest one if choose_largest is true, and the smallest one otherwise.
+// Returns nil if x is not the x co-ordinate of a point on the curve.
 func GetPointFromX(x *fp.Element, choose_largest bool) *PointAffine {
 
 	y := computeY(x, choose_largest)
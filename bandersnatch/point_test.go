@@ -0,0 +1,372 @@
+package bandersnatch
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/crate-crypto/go-ipa/bandersnatch/fp"
+	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
+)
+
+func TestBatchProjToAffine(t *testing.T) {
+	base := GetEdwardsCurve().Base
+	var baseProj PointProj
+	baseProj.FromAffine(&base)
+
+	points := make([]PointProj, 10)
+	for i := range points {
+		var scalar fr.Element
+		scalar.SetRandom()
+		points[i].ScalarMul(&baseProj, &scalar)
+	}
+	points[3].Identity()
+	// Scale the coordinates so that Z != 1.
+	var factor fp.Element
+	factor.SetUint64(7)
+	points[5].X.Mul(&points[5].X, &factor)
+	points[5].Y.Mul(&points[5].Y, &factor)
+	points[5].Z.Mul(&points[5].Z, &factor)
+	// An invalid point with Z = 0 maps to the identity.
+	points[9] = PointProj{}
+
+	got := BatchProjToAffine(points)
+	if len(got) != len(points) {
+		t.Fatalf("expected %d points, got %d", len(points), len(got))
+	}
+	var identity PointAffine
+	identity.Identity()
+	for i := 0; i < len(points)-1; i++ {
+		var expected PointAffine
+		expected.FromProj(&points[i])
+		if !got[i].Equal(&expected) {
+			t.Fatalf("point %d is incorrect", i)
+		}
+	}
+	if !got[9].Equal(&identity) {
+		t.Fatal("a point with Z = 0 should map to the identity")
+	}
+
+	if len(BatchProjToAffine(nil)) != 0 {
+		t.Fatal("converting no points should return an empty slice")
+	}
+}
+
+func TestBatchAdd(t *testing.T) {
+	base := GetEdwardsCurve().Base
+	var baseProj PointProj
+	baseProj.FromAffine(&base)
+
+	points := make([]PointProj, 8)
+	for i := range points {
+		var scalar fr.Element
+		scalar.SetRandom()
+		points[i].ScalarMul(&baseProj, &scalar)
+	}
+	affine := BatchProjToAffine(points)
+
+	var expected PointProj
+	expected.Identity()
+	for i := range points {
+		expected.Add(&expected, &points[i])
+	}
+
+	got := BatchAdd(points)
+	if !got.Equal(&expected) {
+		t.Fatal("BatchAdd doesn't match sequential additions")
+	}
+
+	var identity PointProj
+	identity.Identity()
+	got = BatchMixedAdd(identity, affine)
+	if !got.Equal(&expected) {
+		t.Fatal("BatchMixedAdd doesn't match sequential additions")
+	}
+
+	// The accumulator is added to the sum.
+	got = BatchMixedAdd(baseProj, affine)
+	expected.Add(&expected, &baseProj)
+	if !got.Equal(&expected) {
+		t.Fatal("BatchMixedAdd should add the sum to the base point")
+	}
+
+	got = BatchAdd(nil)
+	if !got.Equal(&identity) {
+		t.Fatal("the sum of no points should be the identity")
+	}
+}
+
+func TestAffineIdentity(t *testing.T) {
+	identity := AffineIdentity()
+	if !identity.X.IsZero() || !identity.Y.IsOne() {
+		t.Fatal("the affine identity should be (0, 1)")
+	}
+	if !identity.IsOnCurve() {
+		t.Fatal("the identity should be on the curve")
+	}
+
+	base := GetEdwardsCurve().Base
+	var sum PointAffine
+	sum.Add(&base, &identity)
+	if !sum.Equal(&base) {
+		t.Fatal("adding the identity should not change a point")
+	}
+}
+
+func TestDouble(t *testing.T) {
+	base := GetEdwardsCurve().Base
+
+	points := []PointAffine{base}
+	for i := 0; i < 10; i++ {
+		var scalar fr.Element
+		scalar.SetRandom()
+		var p PointAffine
+		p.ScalarMul(&base, &scalar)
+		points = append(points, p)
+	}
+	var identity PointAffine
+	identity.Identity()
+	// (0, -1) has order two, so doubling it gives the identity.
+	var orderTwo PointAffine
+	orderTwo.Y.Neg(&identity.Y)
+	points = append(points, identity, orderTwo)
+
+	for i := range points {
+		var got, expected PointAffine
+		got.Double(&points[i])
+		expected.Add(&points[i], &points[i])
+		if !got.Equal(&expected) {
+			t.Fatalf("affine doubling of point %d is incorrect", i)
+		}
+
+		// Doubling in place.
+		inPlace := points[i]
+		inPlace.Double(&inPlace)
+		if !inPlace.Equal(&expected) {
+			t.Fatalf("in place affine doubling of point %d is incorrect", i)
+		}
+
+		var pProj, gotProj, expectedProj PointProj
+		pProj.FromAffine(&points[i])
+		gotProj.Double(&pProj)
+		expectedProj.Add(&pProj, &pProj)
+		if !gotProj.Equal(&expectedProj) {
+			t.Fatalf("projective doubling of point %d is incorrect", i)
+		}
+	}
+
+	var doubledOrderTwo PointAffine
+	doubledOrderTwo.Double(&orderTwo)
+	if !doubledOrderTwo.Equal(&identity) {
+		t.Fatal("doubling a point of order two should give the identity")
+	}
+}
+
+func TestScalarMulWindowed(t *testing.T) {
+	base := GetEdwardsCurve().Base
+	var baseProj PointProj
+	baseProj.FromAffine(&base)
+
+	// 2^i - 1 exercises long runs of ones, which carry when recoding the scalar.
+	var allOnes fr.Element
+	allOnes.SetBigInt(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 250), big.NewInt(1)))
+	scalars := []fr.Element{fr.Zero(), fr.One(), fr.MinusOne(), allOnes}
+	for _, small := range []uint64{2, 7, 8, 9, 15, 16, 17, 255} {
+		var s fr.Element
+		s.SetUint64(small)
+		scalars = append(scalars, s)
+	}
+	for i := 0; i < 20; i++ {
+		var s fr.Element
+		s.SetRandom()
+		scalars = append(scalars, s)
+	}
+
+	for i := range scalars {
+		var got, expected PointProj
+		got.ScalarMulWindowed(&baseProj, &scalars[i])
+		expected.ScalarMul(&baseProj, &scalars[i])
+		if !got.Equal(&expected) {
+			t.Fatalf("windowed scalar multiplication %d is incorrect", i)
+		}
+	}
+
+	// The result can alias the input point.
+	var inPlace, expected PointProj
+	inPlace.Set(&baseProj)
+	inPlace.ScalarMulWindowed(&inPlace, &scalars[5])
+	expected.ScalarMul(&baseProj, &scalars[5])
+	if !inPlace.Equal(&expected) {
+		t.Fatal("in place windowed scalar multiplication is incorrect")
+	}
+}
+
+func TestBatchScalarMul(t *testing.T) {
+	base := GetEdwardsCurve().Base
+	points := make([]PointAffine, 10)
+	scalars := make([]fr.Element, len(points))
+	for i := range points {
+		var scalar fr.Element
+		scalar.SetRandom()
+		points[i].ScalarMul(&base, &scalar)
+		scalars[i].SetRandom()
+	}
+	scalars[0].SetZero()
+	scalars[1].SetOne()
+
+	got := BatchScalarMul(points, scalars)
+	gotAffine := BatchScalarMulAffine(points, scalars)
+	if len(got) != len(points) || len(gotAffine) != len(points) {
+		t.Fatal("expected one product per point")
+	}
+	for i := range points {
+		var point, expected PointProj
+		point.FromAffine(&points[i])
+		expected.ScalarMul(&point, &scalars[i])
+		if !got[i].Equal(&expected) {
+			t.Fatalf("product %d is incorrect", i)
+		}
+		var expectedAffine PointAffine
+		expectedAffine.FromProj(&expected)
+		if !gotAffine[i].Equal(&expectedAffine) {
+			t.Fatalf("affine product %d is incorrect", i)
+		}
+	}
+
+	if len(BatchScalarMul(nil, nil)) != 0 {
+		t.Fatal("expected no products")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for different lengths")
+		}
+	}()
+	BatchScalarMul(points, scalars[1:])
+}
+
+func BenchmarkBatchScalarMul(b *testing.B) {
+	base := GetEdwardsCurve().Base
+	points := make([]PointAffine, 256)
+	scalars := make([]fr.Element, len(points))
+	for i := range points {
+		var scalar fr.Element
+		scalar.SetRandom()
+		points[i].ScalarMul(&base, &scalar)
+		scalars[i].SetRandom()
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		res := make([]PointProj, len(points))
+		for n := 0; n < b.N; n++ {
+			for i := range points {
+				var point PointProj
+				point.FromAffine(&points[i])
+				res[i].ScalarMul(&point, &scalars[i])
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			_ = BatchScalarMul(points, scalars)
+		}
+	})
+}
+
+func BenchmarkScalarMul(b *testing.B) {
+	base := GetEdwardsCurve().Base
+	var baseProj PointProj
+	baseProj.FromAffine(&base)
+	var scalar fr.Element
+	scalar.SetRandom()
+
+	b.Run("double-and-add", func(b *testing.B) {
+		var res PointProj
+		for i := 0; i < b.N; i++ {
+			res.ScalarMul(&baseProj, &scalar)
+		}
+	})
+	b.Run("windowed", func(b *testing.B) {
+		var res PointProj
+		for i := 0; i < b.N; i++ {
+			res.ScalarMulWindowed(&baseProj, &scalar)
+		}
+	})
+}
+
+func TestUncompressedSerialization(t *testing.T) {
+	base := GetEdwardsCurve().Base
+	var identity PointAffine
+	identity.Identity()
+
+	points := []PointAffine{base, identity}
+	for i := 0; i < 5; i++ {
+		var scalar fr.Element
+		scalar.SetRandom()
+		var p PointAffine
+		p.ScalarMul(&base, &scalar)
+		points = append(points, p)
+	}
+
+	for i := range points {
+		encoded := points[i].MarshalUncompressed()
+
+		var buf bytes.Buffer
+		if _, err := points[i].WriteUncompressedPoint(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), encoded[:]) {
+			t.Fatalf("point %d: MarshalUncompressed and WriteUncompressedPoint differ", i)
+		}
+
+		var got PointAffine
+		if err := got.UnmarshalUncompressed(encoded); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(&points[i]) {
+			t.Fatalf("point %d is not equal after (de)serialization", i)
+		}
+	}
+
+	// Changing y takes the point off the curve.
+	offCurve := base.MarshalUncompressed()
+	offCurve[63] ^= 1
+	var got PointAffine
+	if err := got.UnmarshalUncompressed(offCurve); err == nil {
+		t.Fatal("expected an error for a point that isn't on the curve")
+	}
+
+	// x + modulus encodes the same field element, but isn't canonical.
+	nonCanonical := identity.MarshalUncompressed()
+	fp.Modulus().FillBytes(nonCanonical[:32])
+	if err := got.UnmarshalUncompressed(nonCanonical); err == nil {
+		t.Fatal("expected an error for a coordinate that isn't reduced")
+	}
+}
+
+func BenchmarkPointDecoding(b *testing.B) {
+	base := GetEdwardsCurve().Base
+	var scalar fr.Element
+	scalar.SetRandom()
+	var p PointAffine
+	p.ScalarMul(&base, &scalar)
+
+	compressed := p.Bytes()
+	uncompressed := p.MarshalUncompressed()
+
+	b.Run("compressed", func(b *testing.B) {
+		var got PointAffine
+		for i := 0; i < b.N; i++ {
+			if _, err := got.SetBytes(compressed[:]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncompressed", func(b *testing.B) {
+		var got PointAffine
+		for i := 0; i < b.N; i++ {
+			if err := got.UnmarshalUncompressed(uncompressed); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package bandersnatch
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
+	"golang.org/x/sync/errgroup"
+)
+
+// PrecompPoint contains precomputed multiples of a single point, which makes
+// multiplying that point by many scalars much faster than PointProj.ScalarMul.
+type PrecompPoint struct {
+	windowSize int
+	// windows[i][d-1] = d * 2^(i*windowSize) * P, for d in [1, 2^windowSize).
+	windows [][]PointAffine
+}
+
+// NewPrecompPoint precomputes the multiples of p needed to do scalar multiplications
+// with windows of windowSize bits. Bigger windows need fewer additions per
+// multiplication, but the table grows as 2^windowSize.
+func NewPrecompPoint(p PointAffine, windowSize int) (PrecompPoint, error) {
+	if windowSize < 1 || windowSize > 16 {
+		return PrecompPoint{}, fmt.Errorf("window size must be between 1 and 16, got %d", windowSize)
+	}
+
+	numWindows := (fr.Bits + windowSize - 1) / windowSize
+	windows := make([][]PointAffine, numWindows)
+
+	// The multiples of each window are computed in projective coordinates, and then
+	// converted with a single inversion per window.
+	var base PointProj
+	base.FromAffine(&p)
+	multiples := make([]PointProj, 1<<windowSize-1)
+	for i := range windows {
+		multiples[0].Set(&base)
+		for d := 1; d < len(multiples); d++ {
+			multiples[d].Add(&multiples[d-1], &base)
+		}
+		windows[i] = BatchProjToAffine(multiples)
+
+		for j := 0; j < windowSize; j++ {
+			base.Double(&base)
+		}
+	}
+
+	return PrecompPoint{windowSize: windowSize, windows: windows}, nil
+}
+
+// NewPrecompPoints builds a PrecompPoint for each of the points, with the given window
+// size, using up to runtime.NumCPU() goroutines.
+func NewPrecompPoints(points []PointAffine, windowSize int) ([]PrecompPoint, error) {
+	res := make([]PrecompPoint, len(points))
+
+	var group errgroup.Group
+	group.SetLimit(runtime.NumCPU())
+	for i := range points {
+		i := i
+		group.Go(func() error {
+			pp, err := NewPrecompPoint(points[i], windowSize)
+			if err != nil {
+				return fmt.Errorf("precomputing point %d: %s", i, err)
+			}
+			res[i] = pp
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// WindowSize returns the number of scalar bits handled by each window of the table.
+func (pp *PrecompPoint) WindowSize() int {
+	return pp.windowSize
+}
+
+// MemoryUsage returns the number of bytes used by the precomputed table.
+func (pp *PrecompPoint) MemoryUsage() uint64 {
+	var numTablePoints int
+	for _, window := range pp.windows {
+		numTablePoints += len(window)
+	}
+	return uint64(numTablePoints) * uint64(unsafe.Sizeof(PointAffine{}))
+}
+
+// EstimatePrecompPointMemory returns the number of bytes the table of a PrecompPoint
+// built with windowSize would use, without building it.
+func EstimatePrecompPointMemory(windowSize int) uint64 {
+	numWindows := (fr.Bits + windowSize - 1) / windowSize
+	numTablePoints := numWindows * (1<<windowSize - 1)
+	return uint64(numTablePoints) * uint64(unsafe.Sizeof(PointAffine{}))
+}
+
+// ScalarMul sets res to scalar * P, where P is the precomputed point, and returns res.
+func (pp *PrecompPoint) ScalarMul(scalar_mont *fr.Element, res *PointProj) *PointProj {
+	scalar := scalar_mont.ToRegular()
+
+	res.Identity()
+	for i := range pp.windows {
+		var digit int
+		for j := pp.windowSize - 1; j >= 0; j-- {
+			digit <<= 1
+			bit := uint64(i*pp.windowSize + j)
+			if bit < fr.Bits && scalar.Bit(bit) == 1 {
+				digit |= 1
+			}
+		}
+		if digit == 0 {
+			continue
+		}
+		res.MixedAdd(res, &pp.windows[i][digit-1])
+	}
+
+	return res
+}
@@ -0,0 +1,164 @@
+package bandersnatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
+)
+
+func TestPrecompPointScalarMul(t *testing.T) {
+	base := GetEdwardsCurve().Base
+	var baseProj PointProj
+	baseProj.FromAffine(&base)
+
+	scalars := []fr.Element{fr.Zero(), fr.One(), fr.MinusOne()}
+	for i := 0; i < 5; i++ {
+		var s fr.Element
+		s.SetRandom()
+		scalars = append(scalars, s)
+	}
+
+	// 10 doesn't divide the scalar size, so the last window is partial.
+	for _, windowSize := range []int{1, 4, 8, 10} {
+		windowSize := windowSize
+		t.Run(fmt.Sprintf("window %d", windowSize), func(t *testing.T) {
+			pp, err := NewPrecompPoint(base, windowSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range scalars {
+				var got, expected PointProj
+				pp.ScalarMul(&scalars[i], &got)
+				expected.ScalarMul(&baseProj, &scalars[i])
+				if !got.Equal(&expected) {
+					t.Fatalf("scalar multiplication %d is incorrect", i)
+				}
+			}
+		})
+	}
+
+	for _, windowSize := range []int{0, 17} {
+		if _, err := NewPrecompPoint(base, windowSize); err == nil {
+			t.Fatalf("expected an error for window size %d", windowSize)
+		}
+	}
+}
+
+func TestPrecompPointMemoryUsage(t *testing.T) {
+	base := GetEdwardsCurve().Base
+	for _, windowSize := range []int{1, 4, 8, 10} {
+		pp, err := NewPrecompPoint(base, windowSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pp.WindowSize() != windowSize {
+			t.Fatalf("expected window size %d, got %d", windowSize, pp.WindowSize())
+		}
+
+		var numTablePoints int
+		for _, window := range pp.windows {
+			numTablePoints += len(window)
+		}
+		expected := uint64(numTablePoints) * 64
+		if got := pp.MemoryUsage(); got != expected {
+			t.Fatalf("window size %d: expected %d bytes, got %d", windowSize, expected, got)
+		}
+		if got := EstimatePrecompPointMemory(windowSize); got != expected {
+			t.Fatalf("window size %d: estimated %d bytes, expected %d", windowSize, got, expected)
+		}
+	}
+}
+
+func TestNewPrecompPoints(t *testing.T) {
+	base := GetEdwardsCurve().Base
+
+	points := make([]PointAffine, 10)
+	for i := range points {
+		var scalar fr.Element
+		scalar.SetRandom()
+		points[i].ScalarMul(&base, &scalar)
+	}
+
+	pps, err := NewPrecompPoints(points, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pps) != len(points) {
+		t.Fatalf("expected %d precomputed points, got %d", len(points), len(pps))
+	}
+
+	var scalar fr.Element
+	scalar.SetRandom()
+	for i := range points {
+		var pointProj, got, expected PointProj
+		pointProj.FromAffine(&points[i])
+		expected.ScalarMul(&pointProj, &scalar)
+		pps[i].ScalarMul(&scalar, &got)
+		if !got.Equal(&expected) {
+			t.Fatalf("precomputed point %d is incorrect", i)
+		}
+	}
+
+	if _, err := NewPrecompPoints(points, 0); err == nil {
+		t.Fatal("expected an error for an invalid window size")
+	}
+	if pps, err := NewPrecompPoints(nil, 4); err != nil || len(pps) != 0 {
+		t.Fatal("expected no precomputed points")
+	}
+}
+
+func BenchmarkNewPrecompPoint(b *testing.B) {
+	base := GetEdwardsCurve().Base
+	for _, windowSize := range []int{8, 16} {
+		b.Run(fmt.Sprintf("window %d", windowSize), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := NewPrecompPoint(base, windowSize); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewPrecompPoints(b *testing.B) {
+	base := GetEdwardsCurve().Base
+	points := make([]PointAffine, 256)
+	for i := range points {
+		var scalar fr.Element
+		scalar.SetRandom()
+		points[i].ScalarMul(&base, &scalar)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewPrecompPoints(points, 8); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPrecompPointScalarMul(b *testing.B) {
+	base := GetEdwardsCurve().Base
+	var baseProj PointProj
+	baseProj.FromAffine(&base)
+	var scalar fr.Element
+	scalar.SetRandom()
+
+	b.Run("naive", func(b *testing.B) {
+		var res PointProj
+		for i := 0; i < b.N; i++ {
+			res.ScalarMul(&baseProj, &scalar)
+		}
+	})
+	pp, err := NewPrecompPoint(base, 8)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("precomputed", func(b *testing.B) {
+		var res PointProj
+		for i := 0; i < b.N; i++ {
+			pp.ScalarMul(&scalar, &res)
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package banderwagon
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/crate-crypto/go-ipa/bandersnatch"
@@ -11,6 +13,15 @@ import (
 
 const sizePointCompressed = fp.Limbs * 8
 
+var (
+	// ErrInvalidEncoding is returned when the bytes are not a canonical 32 byte encoding of an x co-ordinate.
+	ErrInvalidEncoding = errors.New("invalid point encoding")
+	// ErrNotOnCurve is returned when there is no point on the curve with the given x co-ordinate.
+	ErrNotOnCurve = errors.New("point is not on the curve")
+	// ErrNotInSubgroup is returned when the point is on the curve but not in the banderwagon subgroup.
+	ErrNotInSubgroup = errors.New("point is not in the correct subgroup")
+)
+
 var Generator = Element{inner: bandersnatch.PointProj{
 	X: bandersnatch.GetEdwardsCurve().Base.X,
 	Y: bandersnatch.GetEdwardsCurve().Base.Y,
@@ -29,6 +40,14 @@ type Element struct {
 	inner bandersnatch.PointProj
 }
 
+// Bytes returns the compressed serialisation of the element.
+//
+// A banderwagon element is the equivalence class {(x, y), (-x, -y)}, so it is
+// serialised as x * sign(y): the x co-ordinate of the representative whose y
+// co-ordinate is lexicographically largest, encoded as a 32 byte big-endian integer.
+// See SetBytes for the matching decompression rule.
+//
+// The identity element has x = 0, so it is serialised as 32 zero bytes.
 func (p Element) Bytes() [sizePointCompressed]byte {
 	// Convert underlying point to affine representation
 	var affine_representation bandersnatch.PointAffine
@@ -42,8 +61,35 @@ func (p Element) Bytes() [sizePointCompressed]byte {
 	return x.Bytes()
 }
 
+// IsIdentityBytes returns true if buf is the serialisation of the identity element,
+// ie 32 zero bytes. This allows to detect empty commitments without decompressing them.
+func IsIdentityBytes(buf []byte) bool {
+	if len(buf) != sizePointCompressed {
+		return false
+	}
+	for _, b := range buf {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Serialises multiple group elements using a batch multi inversion
 func ElementsToBytes(elements []*Element) [][sizePointCompressed]byte {
+	serialised_points := make([][sizePointCompressed]byte, len(elements))
+	ElementsToBytesInto(serialised_points, elements)
+	return serialised_points
+}
+
+// ElementsToBytesInto is like ElementsToBytes, but writes the serialised elements
+// into dst, so that callers serialising many batches can reuse the same buffer.
+// panics if len(dst) != len(elements)
+func ElementsToBytesInto(dst [][sizePointCompressed]byte, elements []*Element) {
+	if len(dst) != len(elements) {
+		panic(fmt.Sprintf("destination has length %d, but there are %d elements", len(dst), len(elements)))
+	}
+
 	// Collect all z co-ordinates
 	zs := make([]fp.Element, len(elements))
 	for i := 0; i < int(len(elements)); i++ {
@@ -53,8 +99,6 @@ func ElementsToBytes(elements []*Element) [][sizePointCompressed]byte {
 	// Invert z co-ordinates
 	zInvs := fp.BatchInvert(zs)
 
-	serialised_points := make([][sizePointCompressed]byte, len(elements))
-
 	// Multiply x and y by zInv
 	for i := 0; i < int(len(elements)); i++ {
 		var X fp.Element
@@ -70,19 +114,28 @@ func ElementsToBytes(elements []*Element) [][sizePointCompressed]byte {
 			X.Neg(&X)
 		}
 
-		serialised_points[i] = X.Bytes()
+		dst[i] = X.Bytes()
 	}
-
-	return serialised_points
 }
 
 func (p *Element) setBytes(buf []byte, trusted bool) error {
+	if len(buf) != sizePointCompressed {
+		return ErrInvalidEncoding
+	}
+
 	// set the buffer which is x * SignY as X
 	var x fp.Element
 	x.SetBytes(buf)
+	// Reject values that are not reduced, so that every element has a single encoding.
+	if x_bytes := x.Bytes(); !bytes.Equal(x_bytes[:], buf) {
+		return ErrInvalidEncoding
+	}
+	// Given x there are two valid y co-ordinates, y and -y. We always choose
+	// the one which is lexicographically largest, ie y > (p-1)/2, so that
+	// every implementation decompresses to the same affine representative.
 	point := bandersnatch.GetPointFromX(&x, true)
 	if point == nil {
-		return errors.New("point is not on the curve")
+		return ErrNotOnCurve
 	}
 
 	// subgroup check
@@ -104,12 +157,33 @@ func (p *Element) setBytes(buf []byte, trusted bool) error {
 
 // Deserialises bytes into a group element
 // assuming the input is not trusted
+//
+// The bytes are interpreted as the big-endian x co-ordinate, and the y co-ordinate
+// is chosen to be the lexicographically largest square root, matching Bytes().
+//
+// The input must be exactly 32 bytes encoding a reduced x co-ordinate (ErrInvalidEncoding),
+// of a point on the curve (ErrNotOnCurve), which is in the banderwagon subgroup (ErrNotInSubgroup).
+// The subgroup check is cheap for banderwagon: a point is in the subgroup if and only if 1 - ax^2
+// is a square, so low-order points and points outside the prime order subgroup are rejected.
 func (p *Element) SetBytes(buf []byte) error {
 	return p.setBytes(buf, false)
 }
 
+// MarshalBinary implements encoding.BinaryMarshaler, using the 32 byte
+// compressed serialisation returned by Bytes. It also makes Element usable with encoding/gob.
+func (p Element) MarshalBinary() ([]byte, error) {
+	b := p.Bytes()
+	return b[:], nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// The input is not trusted, see SetBytes for the checks that are performed.
+func (p *Element) UnmarshalBinary(data []byte) error {
+	return p.SetBytes(data)
+}
+
 // Deserialises bytes into a group element
-// assuming the input is trusted
+// assuming the input is trusted, ie the subgroup check is skipped
 func (p *Element) SetBytesTrusted(buf []byte) error {
 	return p.setBytes(buf, true)
 }
@@ -121,6 +195,11 @@ func (p Element) mapToBaseField() fp.Element {
 	return res
 }
 
+// MapToScalarField maps the element to a scalar field element, as done by Verkle trees
+// to commit to child commitments. It computes x/y in the base field, which doesn't
+// depend on the representative (x, y) or (-x, -y) of the element, and reduces its
+// little-endian bytes modulo the scalar field order. The identity maps to zero.
+// See MultiMapToScalarField to map many elements with a single inversion.
 func (p Element) MapToScalarField(res *fr.Element) {
 	basefield := p.mapToBaseField()
 	baseFieldBytes := basefield.BytesLE()
@@ -180,6 +259,42 @@ func (p *Element) Equal(other *Element) bool {
 	return lhs.Equal(&rhs)
 }
 
+// BatchEqual returns, for each index i, whether a[i] is equal to b[i].
+// panics if a and b have different lengths.
+func BatchEqual(a, b []Element) []bool {
+	if len(a) != len(b) {
+		panic(fmt.Sprintf("a has length %d, but b has length %d", len(a), len(b)))
+	}
+	res := make([]bool, len(a))
+	for i := range a {
+		res[i] = a[i].Equal(&b[i])
+	}
+	return res
+}
+
+// Cmp compares the canonical encodings of p and other (see Bytes) lexicographically, and
+// returns -1, 0 or +1. This is a total order on the group, consistent with Equal, which
+// gives a deterministic way to sort commitments.
+// Each call serialises both elements; to sort many of them, it is cheaper to serialise
+// them once with ElementsToBytes and compare the bytes.
+func (p *Element) Cmp(other *Element) int {
+	p_bytes := p.Bytes()
+	other_bytes := other.Bytes()
+	return bytes.Compare(p_bytes[:], other_bytes[:])
+}
+
+// IsInPrimeSubgroup returns true if the element is in the banderwagon prime order subgroup.
+// Elements obtained from SetBytes or from group operations on valid elements always are;
+// this is useful to check elements read with UnsafeReadUncompressedPoint.
+func (p *Element) IsInPrimeSubgroup() bool {
+	if !p.IsOnCurve() {
+		return false
+	}
+	var point_aff bandersnatch.PointAffine
+	point_aff.FromProj(&p.inner)
+	return subgroup_check(point_aff.X) == nil
+}
+
 func subgroup_check(x fp.Element) error {
 	var res, one, ax_sq fp.Element
 	one.SetOne()
@@ -191,17 +306,44 @@ func subgroup_check(x fp.Element) error {
 	res.Sub(&one, &ax_sq)
 
 	if res.Legendre() <= 0 {
-		return errors.New("point is not in the correct subgroup")
+		return ErrNotInSubgroup
 	}
 
 	return nil
 }
 
+// FromProj returns the element represented by the bandersnatch point p, e.g. the result
+// of a multi scalar multiplication done with the bandersnatch package.
+// It returns ErrNotOnCurve or ErrNotInSubgroup if p doesn't represent a valid element.
+func FromProj(p *bandersnatch.PointProj) (Element, error) {
+	element := Element{inner: *p}
+	if !element.IsOnCurve() {
+		return Element{}, ErrNotOnCurve
+	}
+	if !element.IsInPrimeSubgroup() {
+		return Element{}, ErrNotInSubgroup
+	}
+	return element, nil
+}
+
+// ToProj returns a bandersnatch point that represents p. An element has two representatives,
+// (x, y) and (-x, -y), and ToProj can return either of them.
+func (p Element) ToProj() bandersnatch.PointProj {
+	return p.inner
+}
+
 func (p *Element) Identity() *Element {
 	*p = Identity
 	return p
 }
 
+// IsIdentity returns true if p is the identity element.
+// The only points with x = 0 are (0, 1) and (0, -1), which are the two
+// representatives of the identity, so this doesn't need an inversion.
+func (p *Element) IsIdentity() bool {
+	return p.inner.X.IsZero()
+}
+
 func (p *Element) Double(p1 *Element) *Element {
 	p.inner.Double(&p1.inner)
 	return p
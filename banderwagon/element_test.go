@@ -2,7 +2,11 @@ package banderwagon
 
 import (
 	"bytes"
+	"encoding/gob"
 	"encoding/hex"
+	"errors"
+	"math/big"
+	"sort"
 	"testing"
 
 	"github.com/crate-crypto/go-ipa/bandersnatch"
@@ -61,6 +65,205 @@ func TestEncodingFixedVectors(t *testing.T) {
 	}
 }
 
+func TestDecompressionSignRule(t *testing.T) {
+	// Pairs of (serialised point, y co-ordinate chosen on decompression)
+	// The y co-ordinate must be the lexicographically largest square root
+	// so that every implementation decompresses to the same representative.
+	vectors := [][2]string{
+		{"4a2c7486fd924882bf02c6908de395122843e3e05264d7991e18e7985dad51e9", "498140b44f8b3f391dbc4cb74ec5027f1e30f6811119f79ea1ce98483368be9b"},
+		{"43aa74ef706605705989e8fd38df46873b7eae5921fbed115ac9d937399ce4d5", "49c117eec4150059494ba68216769582b5afa98393986bf7efc6fd9ee76fc476"},
+		{"5e5f550494159f38aa54d2ed7f11a7e93e4968617990445cc93ac8e59808c126", "4d840c9949c1d6db033b741531da2a023f0dbf1b2310af9129a578097bed5270"},
+		{"0e7e3748db7c5c999a7bcd93d71d671f1f40090423792266f94cb27ca43fce5c", "563a625521456130dc66f9fd6bda67330c7bb183b7f2223216c1c9536e1c622f"},
+	}
+
+	for _, vector := range vectors {
+		byts, err := hex.DecodeString(vector[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var element Element
+		if err := element.SetBytes(byts); err != nil {
+			t.Fatal(err)
+		}
+
+		var affine bandersnatch.PointAffine
+		affine.FromProj(&element.inner)
+
+		if !affine.Y.LexicographicallyLargest() {
+			t.Fatalf("decompressed y co-ordinate of %s is not the lexicographically largest", vector[0])
+		}
+		y := affine.Y.Bytes()
+		if got := hex.EncodeToString(y[:]); got != vector[1] {
+			t.Fatalf("decompressed y co-ordinate of %s: expected %s got %s", vector[0], vector[1], got)
+		}
+
+		// Decompression must be consistent with compression
+		if element.Bytes() != affine.X.Bytes() {
+			t.Fatalf("recompressing %s does not give back the same bytes", vector[0])
+		}
+	}
+}
+
+func TestIdentityEncoding(t *testing.T) {
+	identity_bytes := Identity.Bytes()
+	if identity_bytes != [sizePointCompressed]byte{} {
+		panic("the identity should be serialised as all zero bytes")
+	}
+	if !IsIdentityBytes(identity_bytes[:]) {
+		panic("the identity bytes were not detected as the identity")
+	}
+
+	var element Element
+	if err := element.SetBytes(identity_bytes[:]); err != nil {
+		panic("could not deserialise the identity")
+	}
+	if !element.Equal(&Identity) {
+		panic("deserialising the identity bytes should give back the identity")
+	}
+	if element.Bytes() != identity_bytes {
+		panic("the identity does not round-trip")
+	}
+
+	if !element.IsIdentity() || !Identity.IsIdentity() {
+		panic("the identity was not detected as the identity")
+	}
+	if Generator.IsIdentity() {
+		panic("the generator was detected as the identity")
+	}
+	// Adding a point to its negation gives the identity, with Z != 1.
+	var neg, sum Element
+	neg.Neg(&Generator)
+	sum.Add(&Generator, &neg)
+	if !sum.IsIdentity() {
+		panic("P - P should be the identity")
+	}
+	// (0, -1) is the other representative of the identity.
+	other := Element{inner: bandersnatch.PointProj{X: fp.Zero(), Y: fp.One(), Z: fp.One()}}
+	other.inner.Y.Neg(&other.inner.Y)
+	if !other.IsIdentity() || !other.Equal(&Identity) {
+		panic("(0, -1) should be the identity")
+	}
+
+	generator_bytes := Generator.Bytes()
+	if IsIdentityBytes(generator_bytes[:]) {
+		panic("the generator was detected as the identity")
+	}
+	if IsIdentityBytes(identity_bytes[:31]) {
+		panic("a short buffer should not be detected as the identity")
+	}
+}
+
+func TestSetBytesRejectsInvalidPoints(t *testing.T) {
+	var element Element
+
+	generator_bytes := Generator.Bytes()
+	if err := element.SetBytes(generator_bytes[:31]); !errors.Is(err, ErrInvalidEncoding) {
+		panic("a short buffer should be rejected as an invalid encoding")
+	}
+
+	// x + p is the same field element as x, but it is not its canonical encoding.
+	var x_plus_p big.Int
+	x_plus_p.SetBytes(generator_bytes[:])
+	x_plus_p.Add(&x_plus_p, fp.Modulus())
+	if x_plus_p.BitLen() <= 256 {
+		var non_canonical [sizePointCompressed]byte
+		x_plus_p.FillBytes(non_canonical[:])
+		if err := element.SetBytes(non_canonical[:]); !errors.Is(err, ErrInvalidEncoding) {
+			panic("a non reduced x co-ordinate should be rejected as an invalid encoding")
+		}
+	}
+
+	// Find x co-ordinates that are not on the curve, and points on the curve
+	// which are not in the subgroup.
+	foundNotOnCurve, foundNotInSubgroup := false, false
+	for i := uint64(1); !foundNotOnCurve || !foundNotInSubgroup; i++ {
+		var x fp.Element
+		x.SetUint64(i)
+		x_bytes := x.Bytes()
+
+		err := element.SetBytes(x_bytes[:])
+		point := bandersnatch.GetPointFromX(&x, true)
+		switch {
+		case point == nil:
+			if !errors.Is(err, ErrNotOnCurve) {
+				panic("a point not on the curve should be rejected")
+			}
+			foundNotOnCurve = true
+		case subgroup_check(x) != nil:
+			if !errors.Is(err, ErrNotInSubgroup) {
+				panic("a point not in the subgroup should be rejected")
+			}
+			// The trusted path skips the check.
+			if err := element.SetBytesTrusted(x_bytes[:]); err != nil {
+				panic(err)
+			}
+			if element.IsInPrimeSubgroup() {
+				panic("the point should not be detected as in the subgroup")
+			}
+			foundNotInSubgroup = true
+		}
+	}
+
+	if !Generator.IsInPrimeSubgroup() || !Identity.IsInPrimeSubgroup() {
+		panic("the generator and the identity are in the subgroup")
+	}
+	var double Element
+	double.Double(&Generator)
+	if !double.IsInPrimeSubgroup() {
+		panic("a multiple of the generator is in the subgroup")
+	}
+}
+
+func TestBinaryAndGobEncoding(t *testing.T) {
+	var random Element
+	var scalar fr.Element
+	scalar.SetRandom()
+	random.ScalarMul(&Generator, &scalar)
+
+	for _, element := range []Element{Identity, Generator, random} {
+		data, err := element.MarshalBinary()
+		if err != nil {
+			panic(err)
+		}
+		var got Element
+		if err := got.UnmarshalBinary(data); err != nil {
+			panic(err)
+		}
+		if !got.Equal(&element) {
+			panic("element does not round-trip through MarshalBinary")
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(element); err != nil {
+			panic(err)
+		}
+		var gobGot Element
+		if err := gob.NewDecoder(&buf).Decode(&gobGot); err != nil {
+			panic(err)
+		}
+		if !gobGot.Equal(&element) {
+			panic("element does not round-trip through gob")
+		}
+	}
+
+	var element Element
+	if err := element.UnmarshalBinary(make([]byte, 31)); err == nil {
+		panic("a short buffer should be rejected")
+	}
+	var x fp.Element
+	for i := uint64(1); ; i++ {
+		x.SetUint64(i)
+		if bandersnatch.GetPointFromX(&x, true) != nil && subgroup_check(x) != nil {
+			break
+		}
+	}
+	x_bytes := x.Bytes()
+	if err := element.UnmarshalBinary(x_bytes[:]); !errors.Is(err, ErrNotInSubgroup) {
+		panic("a point outside of the subgroup should be rejected")
+	}
+}
+
 func TestTwoTorsionEqual(t *testing.T) {
 	// Points that differ by a two torsion point
 	// are equal, where the two torsion point is not the point at infinity
@@ -185,6 +388,60 @@ func TestBatchElementsToBytes(t *testing.T) {
 	}
 }
 
+func TestElementsToBytesInto(t *testing.T) {
+	elements := make([]*Element, 32)
+	for i := range elements {
+		var scalar fr.Element
+		scalar.SetRandom()
+		elements[i] = &Element{}
+		elements[i].ScalarMul(&Generator, &scalar)
+	}
+	elements[7] = &Element{}
+	elements[7].Identity()
+
+	dst := make([][sizePointCompressed]byte, len(elements))
+	// The buffer can be reused across calls.
+	for round := 0; round < 2; round++ {
+		ElementsToBytesInto(dst, elements)
+		for i := range elements {
+			if dst[i] != elements[i].Bytes() {
+				panic("batch serialisation does not match Bytes()")
+			}
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			panic("a destination with the wrong length should panic")
+		}
+	}()
+	ElementsToBytesInto(dst[:1], elements)
+}
+
+func BenchmarkElementsToBytes(b *testing.B) {
+	elements := make([]*Element, 256)
+	for i := range elements {
+		var scalar fr.Element
+		scalar.SetRandom()
+		elements[i] = &Element{}
+		elements[i].ScalarMul(&Generator, &scalar)
+	}
+
+	b.Run("individual", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, element := range elements {
+				_ = element.Bytes()
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		dst := make([][sizePointCompressed]byte, len(elements))
+		for i := 0; i < b.N; i++ {
+			ElementsToBytesInto(dst, elements)
+		}
+	})
+}
+
 func TestMultiMapToBaseField(t *testing.T) {
 	var A, B Element
 
@@ -210,3 +467,151 @@ func TestMultiMapToBaseField(t *testing.T) {
 		panic("expected scalar for point `A` is incorrect ")
 	}
 }
+
+func TestMapToScalarFieldDefinition(t *testing.T) {
+	var scalar fr.Element
+	scalar.SetRandom()
+	var element Element
+	element.ScalarMul(&Generator, &scalar)
+
+	// Compute x/y mod p, then reduce it modulo r, with big integers.
+	var affine bandersnatch.PointAffine
+	affine.FromProj(&element.inner)
+	var x, y, y_inv, expected_big big.Int
+	affine.X.ToBigIntRegular(&x)
+	affine.Y.ToBigIntRegular(&y)
+	y_inv.ModInverse(&y, fp.Modulus())
+	expected_big.Mul(&x, &y_inv)
+	expected_big.Mod(&expected_big, fp.Modulus())
+	var expected fr.Element
+	expected.SetBigInt(&expected_big)
+
+	var got fr.Element
+	element.MapToScalarField(&got)
+	if !got.Equal(&expected) {
+		panic("MapToScalarField should compute x/y reduced modulo the scalar field order")
+	}
+
+	// The other representative of the element maps to the same scalar.
+	var other Element
+	other.inner.X.Neg(&element.inner.X)
+	other.inner.Y.Neg(&element.inner.Y)
+	other.inner.Z.Set(&element.inner.Z)
+	var got_other fr.Element
+	other.MapToScalarField(&got_other)
+	if !got_other.Equal(&got) {
+		panic("both representatives of an element should map to the same scalar")
+	}
+
+	var identity_scalar fr.Element
+	Identity.MapToScalarField(&identity_scalar)
+	if !identity_scalar.IsZero() {
+		panic("the identity should map to zero")
+	}
+}
+
+func TestBatchEqual(t *testing.T) {
+	a := GenerateBasisPoints("batch equal", 4)
+	b := append([]Element{}, a...)
+	b[1].Double(&b[1])
+	// Same element as a[2], with a different representative.
+	b[2].inner.X.Neg(&a[2].inner.X)
+	b[2].inner.Y.Neg(&a[2].inner.Y)
+
+	got := BatchEqual(a, b)
+	expected := []bool{true, false, true, true}
+	for i := range expected {
+		if got[i] != expected[i] {
+			panic("BatchEqual result is incorrect")
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			panic("expected a panic for slices of different lengths")
+		}
+	}()
+	BatchEqual(a, b[1:])
+}
+
+func TestProjConversions(t *testing.T) {
+	var scalar fr.Element
+	scalar.SetUint64(42)
+	var expected Element
+	expected.ScalarMul(&Generator, &scalar)
+
+	generatorProj := Generator.ToProj()
+	var proj bandersnatch.PointProj
+	proj.ScalarMul(&generatorProj, &scalar)
+	got, err := FromProj(&proj)
+	if err != nil {
+		panic(err)
+	}
+	if !got.Equal(&expected) {
+		panic("FromProj(ToProj(G) * 42) should equal G * 42")
+	}
+
+	// Both representatives, (x, y) and (-x, -y), give the same element.
+	proj.X.Neg(&proj.X)
+	proj.Y.Neg(&proj.Y)
+	if got, err := FromProj(&proj); err != nil || !got.Equal(&expected) {
+		panic("the negated representative should give the same element")
+	}
+
+	identityProj := Identity.ToProj()
+	if got, err := FromProj(&identityProj); err != nil || !got.IsIdentity() {
+		panic("the identity should be converted")
+	}
+
+	notOnCurve := bandersnatch.PointProj{X: fp.One(), Y: fp.One(), Z: fp.One()}
+	if _, err := FromProj(&notOnCurve); !errors.Is(err, ErrNotOnCurve) {
+		panic("a point not on the curve should be rejected")
+	}
+
+	for i := uint64(1); ; i++ {
+		var x fp.Element
+		x.SetUint64(i)
+		point := bandersnatch.GetPointFromX(&x, true)
+		if point == nil || subgroup_check(x) == nil {
+			continue
+		}
+		var pointProj bandersnatch.PointProj
+		pointProj.FromAffine(point)
+		if _, err := FromProj(&pointProj); !errors.Is(err, ErrNotInSubgroup) {
+			panic("a point not in the subgroup should be rejected")
+		}
+		break
+	}
+}
+
+func TestCmp(t *testing.T) {
+	points := GenerateBasisPoints("cmp", 16)
+	var identity Element
+	identity.Identity()
+	points = append(points, identity)
+
+	sort.Slice(points, func(i, j int) bool { return points[i].Cmp(&points[j]) < 0 })
+	for i := 1; i < len(points); i++ {
+		prev := points[i-1].Bytes()
+		curr := points[i].Bytes()
+		if bytes.Compare(prev[:], curr[:]) >= 0 {
+			panic("points are not sorted by their encoding")
+		}
+		if points[i].Cmp(&points[i-1]) != 1 || points[i-1].Cmp(&points[i]) != -1 {
+			panic("Cmp is not antisymmetric")
+		}
+	}
+	// The identity encodes to zero, so it sorts first.
+	if !points[0].IsIdentity() {
+		panic("the identity should be the smallest element")
+	}
+
+	// Cmp is consistent with Equal, even for different representatives of the same element.
+	var negated Element
+	negated.inner.X.Neg(&points[3].inner.X)
+	negated.inner.Y.Neg(&points[3].inner.Y)
+	negated.inner.Z = points[3].inner.Z
+	if !negated.Equal(&points[3]) || negated.Cmp(&points[3]) != 0 {
+		panic("equal elements should compare as equal")
+	}
+}
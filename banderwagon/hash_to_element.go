@@ -0,0 +1,139 @@
+package banderwagon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"runtime"
+
+	"github.com/crate-crypto/go-ipa/bandersnatch/fp"
+	"github.com/crate-crypto/go-ipa/common/parallel"
+)
+
+// HashToElement deterministically maps a message to a group element whose discrete
+// log relative to any other element is unknown, so it can be used to generate
+// nothing-up-my-sleeve basis points. dst is a domain separation tag which should be
+// unique to each application.
+//
+// It uses try-and-increment: for counter = 0, 1, 2, ...
+//
+//	h = SHA-256(uint64_be(len(dst)) || dst || msg || uint64_be(counter))
+//
+// h is interpreted as a big-endian integer and reduced modulo the base field to get an
+// x co-ordinate, and the first counter for which SetBytes accepts x (ie x is on the curve
+// and in the banderwagon subgroup) gives the result, which therefore serialises to x.
+// Around a quarter of the candidates are accepted, so only a few hashes are needed in practice.
+func HashToElement(msg, dst []byte) Element {
+	var prefix [8]byte
+	binary.BigEndian.PutUint64(prefix[:], uint64(len(dst)))
+
+	var counter [8]byte
+	for i := uint64(0); ; i++ {
+		binary.BigEndian.PutUint64(counter[:], i)
+
+		digest := sha256.New()
+		digest.Write(prefix[:])
+		digest.Write(dst)
+		digest.Write(msg)
+		digest.Write(counter[:])
+		hash := digest.Sum(nil)
+
+		var x fp.Element
+		x.SetBytes(hash)
+		x_bytes := x.Bytes()
+
+		var element Element
+		if err := element.SetBytes(x_bytes[:]); err == nil {
+			return element
+		}
+	}
+}
+
+// VerkleBasisSeed is the seed of the basis used by the verkle trie specification.
+// In case it changes or needs updating, it follows the eth_verkle_month_year pattern.
+const VerkleBasisSeed = "eth_verkle_oct_2021"
+
+// GenerateBasisPoints deterministically generates n basis points from seed, so that
+// nobody knows the discrete log of any point relative to the others.
+//
+// It uses try-and-increment: for counter = 0, 1, 2, ...
+//
+//	h = SHA-256(seed || uint64_be(counter))
+//
+// h is reduced modulo the base field to get an x co-ordinate, and it is kept as the next
+// point if SetBytes accepts it. GenerateBasisPoints(VerkleBasisSeed, 256) is the SRS of
+// the verkle trie specification.
+//
+// The counters are tried in parallel, in batches, and the accepted points are merged in
+// counter order, so the result is the same as trying them one by one.
+func GenerateBasisPoints(seed string, n uint64) []Element {
+	points := make([]Element, 0, n)
+
+	for next := uint64(0); uint64(len(points)) != n; {
+		// About a quarter of the counters give a point in the subgroup, so this batch
+		// will most likely find all of the remaining points.
+		batchSize := 4 * (n - uint64(len(points)))
+		if numCPU := uint64(runtime.NumCPU()); batchSize < numCPU {
+			batchSize = numCPU
+		}
+
+		candidates := make([]Element, batchSize)
+		valid := make([]bool, batchSize)
+		parallel.Execute(int(batchSize), func(start, end int) {
+			for i := start; i < end; i++ {
+				valid[i] = basisCandidate(seed, next+uint64(i), &candidates[i])
+			}
+		})
+
+		for i := range candidates {
+			if valid[i] && uint64(len(points)) != n {
+				points = append(points, candidates[i])
+			}
+		}
+		next += batchSize
+	}
+
+	return points
+}
+
+// basisCandidate sets point to the candidate of GenerateBasisPoints for the given counter,
+// and returns false if the candidate isn't a valid element.
+func basisCandidate(seed string, counter uint64, point *Element) bool {
+	var counter_bytes [8]byte
+	binary.BigEndian.PutUint64(counter_bytes[:], counter)
+
+	digest := sha256.New()
+	digest.Write([]byte(seed))
+	digest.Write(counter_bytes[:])
+	hash := digest.Sum(nil)
+
+	var x fp.Element
+	x.SetBytes(hash)
+	x_bytes := x.Bytes()
+
+	// The point is not valid if it isn't on the curve or in the correct subgroup.
+	return point.SetBytes(x_bytes[:]) == nil
+}
+
+// ValidateBasis checks that the points can be used as a basis for commitments: each
+// of them must be on the curve, in the prime order subgroup and not the identity, and
+// they must all be different. It returns an error for the first invalid point found.
+// This catches SRS loading bugs that would otherwise only show up as commitments that
+// don't verify.
+func ValidateBasis(points []Element) error {
+	seen := make(map[[sizePointCompressed]byte]int, len(points))
+	for i := range points {
+		if !points[i].IsInPrimeSubgroup() {
+			return fmt.Errorf("point %d is not in the prime order subgroup", i)
+		}
+		if points[i].IsIdentity() {
+			return fmt.Errorf("point %d is the identity", i)
+		}
+		point_bytes := points[i].Bytes()
+		if j, ok := seen[point_bytes]; ok {
+			return fmt.Errorf("point %d is a duplicate of point %d", i, j)
+		}
+		seen[point_bytes] = i
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package banderwagon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestHashToElementVectors(t *testing.T) {
+	// These vectors pin the construction described in HashToElement, so that other
+	// implementations can check that they agree with this one.
+	vectors := []struct {
+		msg, dst string
+		expected string
+	}{
+		{"", "", "084f25bd91e146331399a11ea4c09c212b42d631aeb0b9c982a715a8f3300528"},
+		{"", "go-ipa-test", "49413ef8644ad71ade85f33d27ae8cf0dddddfad08c1e60119cd2c68e3519a60"},
+		{"abc", "go-ipa-test", "313c48c2caae8fab13612a1ae09096eff2bff79d4463ee7307294caa6cbfa9e1"},
+		{"abc", "go-ipa-test-2", "0e57304806f810033d95945ff6ca3015f88c5afc943691ef37cd27b41e125334"},
+		{"hello world", "eth_verkle", "12bee166aa010db306f757b1095d357fda27a7b19ff93cd9aa5442d2b8ca6fb8"},
+	}
+
+	for _, vector := range vectors {
+		element := HashToElement([]byte(vector.msg), []byte(vector.dst))
+		element_bytes := element.Bytes()
+		if got := hex.EncodeToString(element_bytes[:]); got != vector.expected {
+			t.Fatalf("HashToElement(%q, %q) = %s, expected %s", vector.msg, vector.dst, got, vector.expected)
+		}
+
+		// The output is a valid element.
+		var decoded Element
+		if err := decoded.SetBytes(element_bytes[:]); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Equal(&element) {
+			t.Fatal("the element does not round-trip")
+		}
+	}
+}
+
+func TestHashToElementDomainSeparation(t *testing.T) {
+	// The length prefix keeps the tag "ab" with message "c" apart from the tag "a" with message "bc".
+	a := HashToElement([]byte("c"), []byte("ab"))
+	b := HashToElement([]byte("bc"), []byte("a"))
+	if a.Equal(&b) {
+		t.Fatal("moving bytes between the tag and the message should change the output")
+	}
+}
+
+func TestGenerateBasisPoints(t *testing.T) {
+	points := GenerateBasisPoints(VerkleBasisSeed, 256)
+	if len(points) != 256 {
+		t.Fatalf("expected 256 points, got %d", len(points))
+	}
+
+	// Test vectors of the verkle trie specification.
+	vectors := []struct {
+		index    int
+		expected string
+	}{
+		{0, "01587ad1336675eb912550ec2a28eb8923b824b490dd2ba82e48f14590a298a0"},
+		{255, "3de2be346b539395b0c0de56a5ccca54a317f1b5c80107b0802af9a62276a4d8"},
+	}
+	for _, vector := range vectors {
+		point_bytes := points[vector.index].Bytes()
+		if got := hex.EncodeToString(point_bytes[:]); got != vector.expected {
+			t.Fatalf("point %d is %s, expected %s", vector.index, got, vector.expected)
+		}
+	}
+
+	digest := sha256.New()
+	for _, point := range points {
+		point_bytes := point.Bytes()
+		digest.Write(point_bytes[:])
+	}
+	if got := hex.EncodeToString(digest.Sum(nil)); got != "1fcaea10bf24f750200e06fa473c76ff0468007291fa548e2d99f09ba9256fdb" {
+		t.Fatalf("unexpected digest of the points %s", got)
+	}
+
+	// Asking for fewer points gives a prefix of the basis.
+	prefix := GenerateBasisPoints(VerkleBasisSeed, 3)
+	for i := range prefix {
+		if !prefix[i].Equal(&points[i]) {
+			t.Fatalf("point %d differs when generating fewer points", i)
+		}
+	}
+
+	other := GenerateBasisPoints("another seed", 1)
+	if other[0].Equal(&points[0]) {
+		t.Fatal("different seeds should give different points")
+	}
+	if len(GenerateBasisPoints(VerkleBasisSeed, 0)) != 0 {
+		t.Fatal("expected no points")
+	}
+}
+
+func TestValidateBasis(t *testing.T) {
+	points := GenerateBasisPoints(VerkleBasisSeed, 8)
+	if err := ValidateBasis(points); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateBasis(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	withIdentity := append([]Element{}, points...)
+	withIdentity[3].Identity()
+
+	withDuplicate := append([]Element{}, points...)
+	withDuplicate[5] = withDuplicate[1]
+	// -P has a different representative, but it's the same banderwagon element.
+	withNegatedDuplicate := append([]Element{}, points...)
+	withNegatedDuplicate[6].inner.X.Neg(&withNegatedDuplicate[2].inner.X)
+	withNegatedDuplicate[6].inner.Y.Neg(&withNegatedDuplicate[2].inner.Y)
+	withNegatedDuplicate[6].inner.Z = withNegatedDuplicate[2].inner.Z
+
+	// The zero value isn't a valid point, since Z = 0.
+	withZeroValue := append([]Element{}, points...)
+	withZeroValue[0] = Element{}
+
+	withOffCurve := append([]Element{}, points...)
+	withOffCurve[7].inner.X.SetOne()
+	withOffCurve[7].inner.Y.SetOne()
+	withOffCurve[7].inner.Z.SetOne()
+
+	cases := []struct {
+		name     string
+		points   []Element
+		expected string
+	}{
+		{"identity", withIdentity, "point 3 is the identity"},
+		{"duplicate", withDuplicate, "point 5 is a duplicate of point 1"},
+		{"negated duplicate", withNegatedDuplicate, "point 6 is a duplicate of point 2"},
+		{"zero value", withZeroValue, "point 0 is not in the prime order subgroup"},
+		{"off curve", withOffCurve, "point 7 is not in the prime order subgroup"},
+	}
+	for _, c := range cases {
+		err := ValidateBasis(c.points)
+		if err == nil || err.Error() != c.expected {
+			t.Fatalf("%s: expected error %q, got %v", c.name, c.expected, err)
+		}
+	}
+}
+
+func BenchmarkGenerateBasisPoints(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = GenerateBasisPoints(VerkleBasisSeed, 256)
+	}
+}
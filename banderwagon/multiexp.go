@@ -12,13 +12,7 @@ type MultiExpConfig struct {
 }
 
 func (p *Element) MultiExp(points []Element, scalars []fr.Element, _config MultiExpConfig) (*Element, error) {
-	var pointsAffs = make([]bandersnatch.PointAffine, len(points))
-	for i := 0; i < len(points); i++ {
-		// TODO: improve speed by using Montgomery batch normalisation algorithm
-		var AffRepr bandersnatch.PointAffine
-		AffRepr.FromProj(&points[i].inner)
-		pointsAffs[i] = AffRepr
-	}
+	pointsAffs := elements_to_affine(points)
 
 	config := bandersnatch.MultiExpConfig{
 		NbTasks:     _config.NbTasks,
@@ -0,0 +1,36 @@
+package banderwagon
+
+import (
+	"testing"
+
+	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
+)
+
+func TestMultiExp(t *testing.T) {
+	points := make([]Element, 20)
+	scalars := make([]fr.Element, len(points))
+	for i := range points {
+		var r fr.Element
+		r.SetRandom()
+		points[i].ScalarMul(&Generator, &r)
+		scalars[i].SetRandom()
+	}
+	// The identity is a valid input too.
+	points[4].Identity()
+
+	var expected Element
+	expected.Identity()
+	for i := range points {
+		var tmp Element
+		tmp.ScalarMul(&points[i], &scalars[i])
+		expected.Add(&expected, &tmp)
+	}
+
+	var got Element
+	if _, err := got.MultiExp(points, scalars, MultiExpConfig{ScalarsMont: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(&expected) {
+		t.Fatal("multi exponentiation is incorrect")
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math/bits"
+	"runtime"
+	"unsafe"
 
 	"github.com/crate-crypto/go-ipa/bandersnatch"
 	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
@@ -16,9 +19,20 @@ const (
 	// optimized16BitIdxs is how many elements counting from the first element of the SRS we use a 16-bit table.
 	// For the rest of the (numPoints-optimized16bitIdxs) elements we use a 8-bit table.
 	optimized16BitIdxs = 5
+
+	// minParallelCommitLength is the minimum number of evaluations for which
+	// CommitParallel splits the work across goroutines.
+	minParallelCommitLength = 64
+
+	// maxPrecomputeLagrangePoints bounds the number of points accepted when deserializing
+	// a PrecomputeLagrange, so that a corrupted header can't trigger a huge allocation.
+	// It is far larger than any SRS used in practice.
+	maxPrecomputeLagrangePoints = 1 << 16
 )
 
 // PrecomputeLagrange contains precomputed tables for a SRS.
+// The tables are never modified after construction, so a PrecomputeLagrange is
+// safe for concurrent use by multiple goroutines.
 type PrecomputeLagrange struct {
 	// numPoints is the number of points in the SRS.
 	numPoints int
@@ -26,6 +40,9 @@ type PrecomputeLagrange struct {
 	inner16Bit []*LagrangeTablePoints
 	// inner8Bit contains the precomputed tables for the rest of the group elements.
 	inner8Bit []*LagrangeTablePoints
+	// maxCpus is the maximum number of goroutines used by parallel operations.
+	// Zero means runtime.NumCPU().
+	maxCpus int
 }
 
 // Equal returns true if the two PrecomputeLagrange are equal.
@@ -53,10 +70,31 @@ func (pcl PrecomputeLagrange) Equal(other PrecomputeLagrange) bool {
 }
 
 // NewPrecomputeLagrange creates a new PrecomputeLagrange from a set of points.
+// It uses runtime.NumCPU() goroutines to build the tables and in CommitColumns.
 func NewPrecomputeLagrange(points []Element) *PrecomputeLagrange {
-	pl := &PrecomputeLagrange{numPoints: len(points)}
+	return newPrecomputeLagrange(points, 0)
+}
+
+// NewPrecomputeLagrangeWithWorkers is like NewPrecomputeLagrange, but limits to maxWorkers
+// the number of goroutines used to build the tables and in CommitColumns.
+func NewPrecomputeLagrangeWithWorkers(points []Element, maxWorkers int) (*PrecomputeLagrange, error) {
+	if maxWorkers <= 0 {
+		return nil, fmt.Errorf("maxWorkers must be positive, got %d", maxWorkers)
+	}
+	return newPrecomputeLagrange(points, maxWorkers), nil
+}
+
+// newPrecomputeLagrange creates a new PrecomputeLagrange using at most maxCpus goroutines,
+// or runtime.NumCPU() if maxCpus is zero.
+func newPrecomputeLagrange(points []Element, maxCpus int) *PrecomputeLagrange {
+	pl := &PrecomputeLagrange{numPoints: len(points), maxCpus: maxCpus}
 
 	g, _ := errgroup.WithContext(context.Background())
+	if pl.maxCpus != 0 {
+		// Build the 16-bit and 8-bit tables one after the other, so that
+		// we never run more than maxCpus goroutines at the same time.
+		g.SetLimit(1)
+	}
 
 	// Generate 16-bit table for points[:optimized16BitIdx]
 	g.Go(func() error {
@@ -65,7 +103,7 @@ func NewPrecomputeLagrange(points []Element) *PrecomputeLagrange {
 			numPoints = optimized16BitIdxs
 		}
 		table := make([]*LagrangeTablePoints, numPoints)
-		parallel.Execute(numPoints, func(start, end int) {
+		pl.execute(numPoints, func(start, end int) {
 			for i := start; i < end; i++ {
 				// Each window have 1<<16 values, and we have a total of 256/16=16 windows.
 				table[i] = newLagrangeTablePoints(points[i], 256/16, 1<<16)
@@ -80,7 +118,7 @@ func NewPrecomputeLagrange(points []Element) *PrecomputeLagrange {
 		g.Go(func() error {
 			numPoints := len(points) - optimized16BitIdxs
 			table := make([]*LagrangeTablePoints, numPoints)
-			parallel.Execute(numPoints, func(start, end int) {
+			pl.execute(numPoints, func(start, end int) {
 				// We generate the table, but just shifted `optimized16BitIdxs` positions,
 				// since those group elements live in the 16-bit table.
 				for i := start; i < end; i++ {
@@ -133,23 +171,49 @@ func (pcl *PrecomputeLagrange) SerializePrecomputedLagrange(w io.Writer) error {
 // DeserializePrecomputedLagrange deserializes a PrecomputeLagrange.
 // See SerializePrecomputedLagrange() for the format description.
 func DeserializePrecomputedLagrange(reader io.Reader) (*PrecomputeLagrange, error) {
+	return deserializePrecomputedLagrange(reader, -1)
+}
+
+// deserializePrecomputedLagrange deserializes a PrecomputeLagrange, checking that it has
+// expectedNumPoints points before allocating any table. A negative expectedNumPoints
+// skips the check.
+func deserializePrecomputedLagrange(reader io.Reader, expectedNumPoints int) (*PrecomputeLagrange, error) {
 	var pcl PrecomputeLagrange
 
 	var numPoints int64
 	if err := binary.Read(reader, binary.LittleEndian, &numPoints); err != nil {
 		return nil, fmt.Errorf("deserializing the number of points: %s", err)
 	}
+	if numPoints < 0 || numPoints > maxPrecomputeLagrangePoints {
+		return nil, fmt.Errorf("invalid number of points %d", numPoints)
+	}
+	if expectedNumPoints >= 0 && numPoints != int64(expectedNumPoints) {
+		return nil, fmt.Errorf("tables are for %d points, expected %d", numPoints, expectedNumPoints)
+	}
 	pcl.numPoints = int(numPoints)
 
+	// The number of tables of each kind is fully determined by the number of points,
+	// so we check it before allocating anything.
+	expected16BitCount := int64(optimized16BitIdxs)
+	if numPoints < expected16BitCount {
+		expected16BitCount = numPoints
+	}
+	expected8BitCount := numPoints - expected16BitCount
+
 	// 8-bit table deserialization.
 	var table8BitCount int64
 	if err := binary.Read(reader, binary.LittleEndian, &table8BitCount); err != nil {
 		return nil, fmt.Errorf("deserializing the number of points for 8-bit table: %s", err)
 	}
+	if table8BitCount != expected8BitCount {
+		return nil, fmt.Errorf("got %d 8-bit tables for %d points, expected %d", table8BitCount, numPoints, expected8BitCount)
+	}
 	pcl.inner8Bit = make([]*LagrangeTablePoints, table8BitCount)
 	for i := 0; i < int(table8BitCount); i++ {
 		pcl.inner8Bit[i] = &LagrangeTablePoints{}
-		pcl.inner8Bit[i].Deserialize(reader)
+		if err := pcl.inner8Bit[i].deserialize(reader, 256/8, 1<<8-1); err != nil {
+			return nil, fmt.Errorf("deserializing 8-bit table for %d-th point: %s", i, err)
+		}
 	}
 
 	// 16-bit table deserialization.
@@ -157,61 +221,398 @@ func DeserializePrecomputedLagrange(reader io.Reader) (*PrecomputeLagrange, erro
 	if err := binary.Read(reader, binary.LittleEndian, &table16BitCount); err != nil {
 		return nil, fmt.Errorf("deserializing the number of points for 16-bit table: %s", err)
 	}
+	if table16BitCount != expected16BitCount {
+		return nil, fmt.Errorf("got %d 16-bit tables for %d points, expected %d", table16BitCount, numPoints, expected16BitCount)
+	}
 	pcl.inner16Bit = make([]*LagrangeTablePoints, table16BitCount)
 	for i := 0; i < int(table16BitCount); i++ {
 		pcl.inner16Bit[i] = &LagrangeTablePoints{}
-		pcl.inner16Bit[i].Deserialize(reader)
+		if err := pcl.inner16Bit[i].deserialize(reader, 256/16, 1<<16-1); err != nil {
+			return nil, fmt.Errorf("deserializing 16-bit table for %d-th point: %s", i, err)
+		}
 	}
 
 	return &pcl, nil
 }
 
+// precomputeLagrangeMagic and precomputeLagrangeVersion are the header written by WriteTo,
+// so that LoadPrecomputeLagrange can reject files that weren't produced by it.
+var precomputeLagrangeMagic = [8]byte{'g', 'o', '-', 'i', 'p', 'a', 'p', 'l'}
+
+const precomputeLagrangeVersion = uint32(1)
+
+// WriteTo writes the PrecomputeLagrange in a versioned format that can be read back with
+// LoadPrecomputeLagrange. The format is:
+// [magic][uint32(version)][SerializePrecomputedLagrange() output]
+// It implements io.WriterTo.
+func (pcl *PrecomputeLagrange) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write(precomputeLagrangeMagic[:]); err != nil {
+		return cw.n, fmt.Errorf("writing magic: %s", err)
+	}
+	if err := binary.Write(cw, binary.LittleEndian, precomputeLagrangeVersion); err != nil {
+		return cw.n, fmt.Errorf("writing version: %s", err)
+	}
+	if err := pcl.SerializePrecomputedLagrange(cw); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// LoadPrecomputeLagrange reads a PrecomputeLagrange written by WriteTo, checking that
+// it was built for an SRS of numPoints points. Truncated input and tables of the wrong
+// shape return an error, but the table points are not checked to be on the curve, which
+// would slow down loading. Call Validate on the result if the input isn't trusted.
+func LoadPrecomputeLagrange(r io.Reader, numPoints int) (*PrecomputeLagrange, error) {
+	var magic [8]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, fmt.Errorf("reading magic: %s", err)
+	}
+	if magic != precomputeLagrangeMagic {
+		return nil, fmt.Errorf("invalid magic %x", magic)
+	}
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("reading version: %s", err)
+	}
+	if version != precomputeLagrangeVersion {
+		return nil, fmt.Errorf("unsupported version %d, expected %d", version, precomputeLagrangeVersion)
+	}
+
+	return deserializePrecomputedLagrange(r, numPoints)
+}
+
+// Validate checks that every point of the precomputed tables is on the curve. It is
+// not done when deserializing, so that loading trusted tables stays fast.
+func (pcl *PrecomputeLagrange) Validate() error {
+	for i := range pcl.inner16Bit {
+		if err := pcl.inner16Bit[i].validate(); err != nil {
+			return fmt.Errorf("16-bit table for %d-th point: %s", i, err)
+		}
+	}
+	for i := range pcl.inner8Bit {
+		if err := pcl.inner8Bit[i].validate(); err != nil {
+			return fmt.Errorf("8-bit table for %d-th point: %s", i, err)
+		}
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	return n, err
+}
+
 // Commit computes the MSM of a set of evaluations.
+// The evaluations must be reduced, see fr.Element.IsCanonical; this isn't checked.
 func (p *PrecomputeLagrange) Commit(evaluations []fr.Element) Element {
 	var result Element
 	result.Identity()
 
-	// We use p.inner16Bits for the first 5 group elements.
-	for i := 0; i < len(evaluations) && i < len(p.inner16Bit); i++ {
-		scalar := &evaluations[i]
+	for i := range evaluations {
+		p.addScaledPoint(&result, i, &evaluations[i])
+	}
+
+	return result
+}
 
-		if scalar.IsZero() {
-			continue
+// CommitSparse computes the commitment to a vector whose only non-zero evaluations are
+// the ones in `evaluations`, keyed by index. Only those indices are multiplied, so the cost
+// depends on the number of entries rather than on the size of the SRS.
+// It returns an error if an index is not smaller than the number of points in the SRS.
+func (p *PrecomputeLagrange) CommitSparse(evaluations map[int]fr.Element) (Element, error) {
+	for i := range evaluations {
+		if i < 0 || i >= p.numPoints {
+			return Element{}, fmt.Errorf("index %d is out of range, there are %d points", i, p.numPoints)
+		}
+	}
+
+	var result Element
+	result.Identity()
+	for i, evaluation := range evaluations {
+		evaluation := evaluation
+		p.addScaledPoint(&result, i, &evaluation)
+	}
+
+	return result, nil
+}
+
+// CommitWithIndices computes the same commitment as Commit, trusting the caller that
+// evaluations[i] is zero for every index i not in nonzero, e.g. because it already knows
+// which values changed. Only the listed indices are read, and each must appear once.
+// It returns an error if an index is outside of evaluations or of the SRS.
+func (p *PrecomputeLagrange) CommitWithIndices(evaluations []fr.Element, nonzero []int) (Element, error) {
+	for _, i := range nonzero {
+		if i < 0 || i >= len(evaluations) || i >= p.numPoints {
+			return Element{}, fmt.Errorf("index %d is out of range, there are %d evaluations and %d points", i, len(evaluations), p.numPoints)
 		}
+	}
 
-		table := p.inner16Bit[i]
-		scalar_bytes_le := scalar.BytesLE()
+	var result Element
+	result.Identity()
+	for _, i := range nonzero {
+		p.addScaledPoint(&result, i, &evaluations[i])
+	}
+
+	return result, nil
+}
+
+// CommitParallel computes the same commitment as Commit, but splits the evaluations
+// across goroutines and sums the partial commitments. Vectors shorter than
+// minParallelCommitLength are committed serially, since for them the goroutines cost
+// more than they save.
+func (p *PrecomputeLagrange) CommitParallel(evaluations []fr.Element) Element {
+	if len(evaluations) < minParallelCommitLength {
+		return p.Commit(evaluations)
+	}
+	return p.commitParallel(evaluations)
+}
+
+func (p *PrecomputeLagrange) commitParallel(evaluations []fr.Element) Element {
+	return p.commitFuncParallel(len(evaluations), func(i int) fr.Element { return evaluations[i] })
+}
+
+// CommitFunc computes the commitment to the n evaluations returned by get, without
+// materializing them in a slice, which bounds memory when they are derived on the fly
+// (e.g. from a leaf iterator). Like CommitParallel, index ranges are split across
+// goroutines, so get must be safe for concurrent use; it's called once per index.
+// panics if n is negative or greater than the number of points in the SRS.
+func (p *PrecomputeLagrange) CommitFunc(n int, get func(i int) fr.Element) Element {
+	if n < 0 || n > p.numPoints {
+		panic(fmt.Sprintf("cannot commit to %d evaluations, there are %d points", n, p.numPoints))
+	}
+	if n < minParallelCommitLength {
+		var result Element
+		result.Identity()
+		for i := 0; i < n; i++ {
+			evaluation := get(i)
+			p.addScaledPoint(&result, i, &evaluation)
+		}
+		return result
+	}
+	return p.commitFuncParallel(n, get)
+}
+
+// commitFuncParallel splits the n evaluations in one chunk per goroutine, and sums the
+// partial commitments in chunk order once all of them are done, so that the result
+// doesn't depend on which goroutine finishes first.
+func (p *PrecomputeLagrange) commitFuncParallel(n int, get func(i int) fr.Element) Element {
+	numChunks := p.maxCpus
+	if numChunks == 0 {
+		numChunks = runtime.NumCPU()
+	}
+	if numChunks > n {
+		numChunks = n
+	}
+
+	partials := make([]Element, numChunks)
+	p.execute(numChunks, func(start, end int) {
+		for chunk := start; chunk < end; chunk++ {
+			partials[chunk].Identity()
+			for i := chunk * n / numChunks; i < (chunk+1)*n/numChunks; i++ {
+				evaluation := get(i)
+				p.addScaledPoint(&partials[chunk], i, &evaluation)
+			}
+		}
+	})
+
+	var result Element
+	result.Identity()
+	for i := range partials {
+		result.Add(&result, &partials[i])
+	}
+
+	return result
+}
 
+// UpdateCommitment updates a commitment to a set of evaluations when only some of them change.
+// changes[i] is the delta to add to the i-th evaluation, ie new_i - old_i, so only the changed
+// indices are multiplied instead of recomputing the whole commitment.
+func (p *PrecomputeLagrange) UpdateCommitment(old Element, changes map[int]fr.Element) (Element, error) {
+	for i := range changes {
+		if i < 0 || i >= p.numPoints {
+			return Element{}, fmt.Errorf("index %d is out of range, there are %d points", i, p.numPoints)
+		}
+	}
+
+	result := old
+	for i, delta := range changes {
+		delta := delta
+		p.addScaledPoint(&result, i, &delta)
+	}
+
+	return result, nil
+}
+
+// UpdateCommitmentAt updates commitment c after the evaluation at index changes from oldVal
+// to newVal, by adding (newVal-oldVal) times the index-th point of the SRS. This is what a
+// verkle tree does when a single leaf value changes.
+// It returns an error if index is not smaller than the number of points in the SRS.
+func (p *PrecomputeLagrange) UpdateCommitmentAt(c Element, index int, oldVal, newVal fr.Element) (Element, error) {
+	if index < 0 || index >= p.numPoints {
+		return Element{}, fmt.Errorf("index %d is out of range, there are %d points", index, p.numPoints)
+	}
+
+	var delta fr.Element
+	delta.Sub(&newVal, &oldVal)
+	p.addScaledPoint(&c, index, &delta)
+
+	return c, nil
+}
+
+// addScaledPoint adds scalar times the i-th point of the SRS to result.
+// We use p.inner16Bits for the first 5 group elements, and p.inner8Bits for the rest.
+func (p *PrecomputeLagrange) addScaledPoint(result *Element, i int, scalar *fr.Element) {
+	if scalar.IsZero() {
+		return
+	}
+	scalar_bytes_le := scalar.BytesLE()
+
+	if i < len(p.inner16Bit) {
+		table := p.inner16Bit[i]
 		for row := 0; row < 16; row++ {
 			value := uint16(scalar_bytes_le[2*row]) + uint16(scalar_bytes_le[2*row+1])<<8
 			if value == 0 {
 				continue
 			}
 			tp := table.point(row, value)
-			result.AddMixed(&result, *tp)
+			result.AddMixed(result, *tp)
 		}
+		return
 	}
 
-	// We use p.inner8Bits for the rest of the elements.
-	for i := len(p.inner16Bit); i < len(evaluations); i++ {
-		scalar := &evaluations[i]
-
-		if scalar.IsZero() {
+	table := p.inner8Bit[i-len(p.inner16Bit)]
+	for row, value := range scalar_bytes_le {
+		if value == 0 {
 			continue
 		}
-		table := p.inner8Bit[i-len(p.inner16Bit)]
-		scalar_bytes_le := scalar.BytesLE()
+		tp := table.point(row, uint16(value))
+		result.AddMixed(result, *tp)
+	}
+}
 
-		for row, value := range scalar_bytes_le {
-			if value == 0 {
-				continue
-			}
-			tp := table.point(row, uint16(value))
-			result.AddMixed(&result, *tp)
+// MaxLength returns the maximum number of evaluations that can be committed,
+// which is the number of points in the SRS.
+func (p *PrecomputeLagrange) MaxLength() int {
+	return p.numPoints
+}
+
+// CanHandle returns true if a vector of n evaluations can be committed.
+func (p *PrecomputeLagrange) CanHandle(n int) bool {
+	return n >= 0 && n <= p.numPoints
+}
+
+// MemoryUsage returns the number of bytes used by the precomputed tables.
+func (p *PrecomputeLagrange) MemoryUsage() uint64 {
+	var numTablePoints int
+	for _, table := range p.inner16Bit {
+		numTablePoints += len(table.matrix)
+	}
+	for _, table := range p.inner8Bit {
+		numTablePoints += len(table.matrix)
+	}
+	return uint64(numTablePoints) * uint64(unsafe.Sizeof(bandersnatch.PointAffine{}))
+}
+
+// EstimatePrecomputeLagrangeMemory returns the number of bytes the precomputed tables of
+// a PrecomputeLagrange built for numPoints points would use, without building it.
+// This can be used to check that there is enough memory before calling NewPrecomputeLagrange.
+func EstimatePrecomputeLagrangeMemory(numPoints int) uint64 {
+	num16Bit := numPoints
+	if num16Bit > optimized16BitIdxs {
+		num16Bit = optimized16BitIdxs
+	}
+	num8Bit := numPoints - num16Bit
+
+	// Each table has one point per non-zero value of each window, see newLagrangeTablePoints.
+	numTablePoints := num16Bit*(256/16)*(1<<16-1) + num8Bit*(256/8)*(1<<8-1)
+	return uint64(numTablePoints) * uint64(unsafe.Sizeof(bandersnatch.PointAffine{}))
+}
+
+// CommitInt64 commits to a vector of signed integers.
+// Negative values are mapped to their field representation, ie -v is committed as q - v.
+func (p *PrecomputeLagrange) CommitInt64(values []int64) Element {
+	evaluations := make([]fr.Element, len(values))
+	for i, v := range values {
+		evaluations[i].SetInt64(v)
+	}
+	return p.Commit(evaluations)
+}
+
+// CommitBatch computes the commitment of every vector of evaluations.
+// The work is split across vectors rather than within each commitment, which is
+// more efficient when committing many vectors against the same SRS.
+// No vector can be longer than the number of points in the SRS.
+func (p *PrecomputeLagrange) CommitBatch(vectors [][]fr.Element) ([]Element, error) {
+	for i := range vectors {
+		if len(vectors[i]) > p.numPoints {
+			return nil, fmt.Errorf("vector %d has %d evaluations but there are only %d points", i, len(vectors[i]), p.numPoints)
 		}
 	}
 
-	return result
+	result := make([]Element, len(vectors))
+	if len(vectors) == 0 {
+		return result, nil
+	}
+	p.execute(len(vectors), func(start, end int) {
+		for i := start; i < end; i++ {
+			result[i] = p.Commit(vectors[i])
+		}
+	})
+
+	return result, nil
+}
+
+// CommitColumns computes the commitment of every column of a matrix of evaluations.
+// Each inner slice of `matrix` is a row, so row i is committed with the i-th point of
+// the SRS, and the j-th returned element is the commitment to the j-th column.
+// All rows must have the same length, and there can't be more rows than points in the SRS.
+func (p *PrecomputeLagrange) CommitColumns(matrix [][]fr.Element) ([]Element, error) {
+	if len(matrix) > p.numPoints {
+		return nil, fmt.Errorf("the matrix has %d rows but there are only %d points", len(matrix), p.numPoints)
+	}
+	if len(matrix) == 0 {
+		return []Element{}, nil
+	}
+	numColumns := len(matrix[0])
+	for i := range matrix {
+		if len(matrix[i]) != numColumns {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d", i, len(matrix[i]), numColumns)
+		}
+	}
+
+	result := make([]Element, numColumns)
+	if numColumns == 0 {
+		return result, nil
+	}
+	p.execute(numColumns, func(start, end int) {
+		// Each worker reuses the same buffer to hold the column it is committing,
+		// so we avoid transposing the whole matrix.
+		column := make([]fr.Element, len(matrix))
+		for j := start; j < end; j++ {
+			for i := range matrix {
+				column[i] = matrix[i][j]
+			}
+			result[j] = p.Commit(column)
+		}
+	})
+
+	return result, nil
+}
+
+// execute runs work in parallel, honouring the configured maximum number of goroutines.
+func (p *PrecomputeLagrange) execute(nbIterations int, work func(int, int)) {
+	if p.maxCpus == 0 {
+		parallel.Execute(nbIterations, work)
+		return
+	}
+	parallel.Execute(nbIterations, work, p.maxCpus)
 }
 
 type LagrangeTablePoints struct {
@@ -245,19 +646,51 @@ func (ltp *LagrangeTablePoints) Serialize(w io.Writer) error {
 // Deserialize deserializes a LagrangeTablePoints.
 // See (*LagrangeTablePoints).Serialize() for the format description.
 func (ltp *LagrangeTablePoints) Deserialize(r io.Reader) error {
+	return ltp.deserialize(r, 0, 0)
+}
+
+// deserialize deserializes a LagrangeTablePoints, checking that it has the expected
+// number of rows and window size. A zero numRows or windowSize skips the corresponding check.
+func (ltp *LagrangeTablePoints) deserialize(r io.Reader, numRows int, windowSize int) error {
 	var columnCount int64
 	if err := binary.Read(r, binary.LittleEndian, &columnCount); err != nil {
 		return fmt.Errorf("deserializing the number of columns: %s", err)
 	}
-	var windowSize int64
-	if err := binary.Read(r, binary.LittleEndian, &windowSize); err != nil {
+	var readWindowSize int64
+	if err := binary.Read(r, binary.LittleEndian, &readWindowSize); err != nil {
 		return fmt.Errorf("deserializing window size: %s", err)
 	}
+	if readWindowSize <= 0 || columnCount < 0 || columnCount%readWindowSize != 0 {
+		return fmt.Errorf("invalid table with %d points and window size %d", columnCount, readWindowSize)
+	}
+	if windowSize != 0 && readWindowSize != int64(windowSize) {
+		return fmt.Errorf("got window size %d, expected %d", readWindowSize, windowSize)
+	}
+	if numRows != 0 && columnCount != int64(numRows*windowSize) {
+		return fmt.Errorf("got %d points, expected %d", columnCount, numRows*windowSize)
+	}
 	ltp.identity.Identity()
-	ltp.windowSize = int(windowSize)
+	ltp.windowSize = int(readWindowSize)
 	ltp.matrix = make([]bandersnatch.PointAffine, columnCount)
+	// Points are only decoded, not checked to be on the curve, since that would make
+	// loading the tables much slower. See PrecomputeLagrange.Validate.
+	var buf [64]byte
+	for i := range ltp.matrix {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return fmt.Errorf("reading point %d: %s", i, err)
+		}
+		ltp.matrix[i].X.SetBytes(buf[:32])
+		ltp.matrix[i].Y.SetBytes(buf[32:])
+	}
+	return nil
+}
+
+// validate checks that every point of the table is on the curve.
+func (ltp *LagrangeTablePoints) validate() error {
 	for i := range ltp.matrix {
-		ltp.matrix[i] = bandersnatch.ReadUncompressedPoint(r)
+		if !ltp.matrix[i].IsOnCurve() {
+			return fmt.Errorf("point %d is not on the curve", i)
+		}
 	}
 	return nil
 }
@@ -294,12 +727,22 @@ func newLagrangeTablePoints(point Element, num_rows int, base_int int) *Lagrange
 	rows := make([]Element, 0, num_rows*(base_int-1))
 	rows = append(rows, base_row...)
 
-	scale := base
-	// TODO: we can do this in parallel
-	for i := 1; i < num_rows; i++ {
-		scaled_row := scale_row(base_row, scale)
-		rows = append(rows, scaled_row...)
-		scale.Mul(&scale, &base)
+	if base_int&(base_int-1) == 0 {
+		// The base is a power of two, so each row is the previous one doubled
+		// log2(base) times, which is much cheaper than a scalar multiplication.
+		log_base := bits.TrailingZeros(uint(base_int))
+		prev_row := base_row
+		for i := 1; i < num_rows; i++ {
+			prev_row = double_row(prev_row, log_base)
+			rows = append(rows, prev_row...)
+		}
+	} else {
+		scale := base
+		for i := 1; i < num_rows; i++ {
+			scaled_row := scale_row(base_row, scale)
+			rows = append(rows, scaled_row...)
+			scale.Mul(&scale, &base)
+		}
 	}
 	rows_affine := elements_to_affine(rows)
 	var identity bandersnatch.PointAffine
@@ -330,6 +773,18 @@ func compute_base_row(point Element, num_points int) []Element {
 	return row
 }
 
+// double_row returns the points doubled `times` times, ie multiplied by 2^times.
+func double_row(points []Element, times int) []Element {
+	doubled_points := make([]Element, len(points))
+	for i := 0; i < len(points); i++ {
+		doubled_points[i] = points[i]
+		for j := 0; j < times; j++ {
+			doubled_points[i].Double(&doubled_points[i])
+		}
+	}
+	return doubled_points
+}
+
 func scale_row(points []Element, scale fr.Element) []Element {
 	scaled_points := make([]Element, len(points))
 	for i := 0; i < len(points); i++ {
@@ -340,14 +795,17 @@ func scale_row(points []Element, scale fr.Element) []Element {
 	return scaled_points
 }
 
-func elements_to_affine(points []Element) []bandersnatch.PointAffine {
-	affine_points := make([]bandersnatch.PointAffine, len(points))
+// BatchToAffine returns the affine representation of each element, using a single
+// field inversion for all of them.
+func BatchToAffine(points []Element) []bandersnatch.PointAffine {
+	return elements_to_affine(points)
+}
 
+func elements_to_affine(points []Element) []bandersnatch.PointAffine {
+	proj_points := make([]bandersnatch.PointProj, len(points))
 	for index, point := range points {
-		var affine bandersnatch.PointAffine
-		affine.FromProj(&point.inner)
-		affine_points[index] = affine
+		proj_points[index] = point.inner
 	}
 
-	return affine_points
+	return bandersnatch.BatchProjToAffine(proj_points)
 }
@@ -0,0 +1,769 @@
+package banderwagon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
+)
+
+func TestCommitColumns(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	numRows, numColumns := 10, 7
+	matrix := make([][]fr.Element, numRows)
+	for i := range matrix {
+		matrix[i] = make([]fr.Element, numColumns)
+		for j := range matrix[i] {
+			matrix[i][j].SetRandom()
+		}
+	}
+	// Leave a zero column to exercise the identity case.
+	for i := range matrix {
+		matrix[i][3].SetZero()
+	}
+
+	got, err := precomp.CommitColumns(matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != numColumns {
+		t.Fatalf("expected %d commitments, got %d", numColumns, len(got))
+	}
+	for j := 0; j < numColumns; j++ {
+		column := make([]fr.Element, numRows)
+		for i := range column {
+			column[i] = matrix[i][j]
+		}
+		expected := precomp.Commit(column)
+		if !expected.Equal(&got[j]) {
+			t.Fatalf("commitment of column %d is incorrect", j)
+		}
+	}
+
+	// Limiting the number of goroutines doesn't change the result.
+	limited := *precomp
+	limited.maxCpus = 2
+	gotLimited, err := limited.CommitColumns(matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range got {
+		if !got[j].Equal(&gotLimited[j]) {
+			t.Fatalf("commitment of column %d differs when limiting the number of goroutines", j)
+		}
+	}
+
+	// Ragged rows are rejected.
+	matrix[4] = matrix[4][:numColumns-1]
+	if _, err := precomp.CommitColumns(matrix); err == nil {
+		t.Fatal("expected an error for rows of different lengths")
+	}
+
+	// More rows than points in the SRS are rejected.
+	tooManyRows := make([][]fr.Element, testPrecomputeNumPoints+1)
+	if _, err := precomp.CommitColumns(tooManyRows); err == nil {
+		t.Fatal("expected an error for a matrix with more rows than points")
+	}
+}
+
+func TestCommitBatch(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	vectors := make([][]fr.Element, 9)
+	for i := range vectors {
+		// Vectors of different lengths, including an empty one.
+		vectors[i] = make([]fr.Element, i)
+		for j := range vectors[i] {
+			vectors[i][j].SetRandom()
+		}
+	}
+
+	got, err := precomp.CommitBatch(vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(vectors) {
+		t.Fatalf("expected %d commitments, got %d", len(vectors), len(got))
+	}
+	for i := range vectors {
+		expected := precomp.Commit(vectors[i])
+		if !expected.Equal(&got[i]) {
+			t.Fatalf("commitment of vector %d is incorrect", i)
+		}
+	}
+
+	// Vectors longer than the SRS are rejected.
+	vectors = append(vectors, make([]fr.Element, testPrecomputeNumPoints+1))
+	if _, err := precomp.CommitBatch(vectors); err == nil {
+		t.Fatal("expected an error for a vector longer than the SRS")
+	}
+}
+
+func BenchmarkCommitBatch(b *testing.B) {
+	precomp := getTestPrecompute()
+
+	vectors := make([][]fr.Element, 1024)
+	for i := range vectors {
+		vectors[i] = make([]fr.Element, testPrecomputeNumPoints)
+		for j := range vectors[i] {
+			vectors[i][j].SetRandom()
+		}
+	}
+
+	b.Run("loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range vectors {
+				_ = precomp.Commit(vectors[j])
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := precomp.CommitBatch(vectors); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestUpdateCommitment(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	evaluations := make([]fr.Element, testPrecomputeNumPoints)
+	for i := range evaluations {
+		evaluations[i].SetRandom()
+	}
+	old := precomp.Commit(evaluations)
+
+	// Change a value in the 16-bit tables and another one in the 8-bit tables.
+	changes := map[int]fr.Element{}
+	for _, i := range []int{1, testPrecomputeNumPoints - 1} {
+		var newValue, delta fr.Element
+		newValue.SetRandom()
+		delta.Sub(&newValue, &evaluations[i])
+		changes[i] = delta
+		evaluations[i] = newValue
+	}
+
+	got, err := precomp.UpdateCommitment(old, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := precomp.Commit(evaluations)
+	if !got.Equal(&expected) {
+		t.Fatal("updated commitment is incorrect")
+	}
+
+	// An index outside the SRS is rejected.
+	if _, err := precomp.UpdateCommitment(old, map[int]fr.Element{testPrecomputeNumPoints: fr.One()}); err == nil {
+		t.Fatal("expected an error for an out of range index")
+	}
+}
+
+func TestUpdateCommitmentAt(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	evaluations := make([]fr.Element, testPrecomputeNumPoints)
+	for i := range evaluations {
+		evaluations[i].SetRandom()
+	}
+	commitment := precomp.Commit(evaluations)
+
+	// Apply a sequence of single updates, touching some indices more than once and
+	// using the largest 16-bit and 8-bit table indices.
+	indices := []int{0, optimized16BitIdxs - 1, optimized16BitIdxs, 3, testPrecomputeNumPoints - 1, 3, 0}
+	for _, index := range indices {
+		var newVal fr.Element
+		newVal.SetRandom()
+		var err error
+		commitment, err = precomp.UpdateCommitmentAt(commitment, index, evaluations[index], newVal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evaluations[index] = newVal
+	}
+
+	// Setting an evaluation to its current value leaves the commitment unchanged.
+	commitment, err := precomp.UpdateCommitmentAt(commitment, 1, evaluations[1], evaluations[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := precomp.Commit(evaluations)
+	if !commitment.Equal(&expected) {
+		t.Fatal("sequential updates don't match a fresh commitment")
+	}
+
+	if _, err := precomp.UpdateCommitmentAt(commitment, testPrecomputeNumPoints, fr.Zero(), fr.One()); err == nil {
+		t.Fatal("expected an error for an out of range index")
+	}
+}
+
+func BenchmarkUpdateCommitment(b *testing.B) {
+	precomp := getTestPrecompute()
+
+	evaluations := make([]fr.Element, testPrecomputeNumPoints)
+	for i := range evaluations {
+		evaluations[i].SetRandom()
+	}
+	old := precomp.Commit(evaluations)
+	var delta fr.Element
+	delta.SetRandom()
+	changes := map[int]fr.Element{testPrecomputeNumPoints - 1: delta}
+
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = precomp.Commit(evaluations)
+		}
+	})
+	b.Run("single change", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := precomp.UpdateCommitment(old, changes); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCommitConcurrent(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	const numGoroutines = 32
+	vectors := make([][]fr.Element, numGoroutines)
+	expected := make([]Element, numGoroutines)
+	for i := range vectors {
+		vectors[i] = make([]fr.Element, testPrecomputeNumPoints)
+		for j := range vectors[i] {
+			vectors[i][j].SetRandom()
+		}
+		expected[i] = precomp.Commit(vectors[i])
+	}
+
+	got := make([]Element, numGoroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = precomp.Commit(vectors[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range got {
+		if !got[i].Equal(&expected[i]) {
+			t.Fatalf("concurrent commitment %d doesn't match the serial one", i)
+		}
+	}
+}
+
+func TestCommitSparse(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	// Entries in both the 16-bit and the 8-bit tables, and an explicit zero.
+	sparse := map[int]fr.Element{0: {}, 2: {}, 9: {}, testPrecomputeNumPoints - 1: {}}
+	dense := make([]fr.Element, testPrecomputeNumPoints)
+	for i := range sparse {
+		if i == 0 {
+			continue
+		}
+		var v fr.Element
+		v.SetRandom()
+		sparse[i] = v
+		dense[i] = v
+	}
+
+	got, err := precomp.CommitSparse(sparse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := precomp.Commit(dense)
+	if !got.Equal(&expected) {
+		t.Fatal("sparse commitment doesn't match the dense one")
+	}
+
+	empty, err := precomp.CommitSparse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !empty.Equal(&Identity) {
+		t.Fatal("committing to no evaluations should give the identity")
+	}
+
+	if _, err := precomp.CommitSparse(map[int]fr.Element{testPrecomputeNumPoints: fr.One()}); err == nil {
+		t.Fatal("expected an error for an out of range index")
+	}
+}
+
+func TestCommitWithIndices(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	// Indices in both the 16-bit and the 8-bit tables, out of order.
+	nonzero := []int{testPrecomputeNumPoints - 1, 2, 9, 0}
+	evaluations := make([]fr.Element, testPrecomputeNumPoints)
+	for _, i := range nonzero {
+		evaluations[i].SetRandom()
+	}
+
+	got, err := precomp.CommitWithIndices(evaluations, nonzero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := precomp.Commit(evaluations)
+	if !got.Equal(&expected) {
+		t.Fatal("commitment with indices doesn't match Commit")
+	}
+
+	empty, err := precomp.CommitWithIndices(evaluations, nil)
+	if err != nil || !empty.Equal(&Identity) {
+		t.Fatal("committing to no indices should give the identity")
+	}
+
+	for _, i := range []int{-1, testPrecomputeNumPoints} {
+		if _, err := precomp.CommitWithIndices(evaluations, []int{0, i}); err == nil {
+			t.Fatalf("expected an error for index %d", i)
+		}
+	}
+	if _, err := precomp.CommitWithIndices(evaluations[:5], []int{5}); err == nil {
+		t.Fatal("expected an error for an index outside of the evaluations")
+	}
+}
+
+func TestCommitParallel(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	for _, n := range []int{0, 1, testPrecomputeNumPoints} {
+		evaluations := make([]fr.Element, n)
+		for i := range evaluations {
+			evaluations[i].SetRandom()
+		}
+		expected := precomp.Commit(evaluations)
+		got := precomp.CommitParallel(evaluations)
+		if !got.Equal(&expected) {
+			t.Fatalf("parallel commitment of %d evaluations is incorrect", n)
+		}
+		// The test SRS is shorter than minParallelCommitLength, so also check
+		// the parallel path directly.
+		got = precomp.commitParallel(evaluations)
+		if !got.Equal(&expected) {
+			t.Fatalf("parallel commitment of %d evaluations is incorrect", n)
+		}
+		// The partial commitments are summed in a fixed order, so even the projective
+		// representation doesn't change between runs.
+		for i := 0; i < 10; i++ {
+			if again := precomp.commitParallel(evaluations); again.inner != got.inner {
+				t.Fatalf("parallel commitment of %d evaluations isn't deterministic", n)
+			}
+		}
+	}
+}
+
+// TestCommitLengths checks commitments to vectors shorter than the SRS, in particular
+// around optimized16BitIdxs where Commit switches from the 16-bit to the 8-bit tables.
+func TestCommitLengths(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	for _, n := range []int{1, 3, optimized16BitIdxs - 1, optimized16BitIdxs, optimized16BitIdxs + 1, 7, testPrecomputeNumPoints} {
+		evaluations := make([]fr.Element, n)
+		for i := range evaluations {
+			evaluations[i].SetRandom()
+		}
+
+		var expected Element
+		if _, err := expected.MultiExp(testPrecomputePoints[:n], evaluations, MultiExpConfig{ScalarsMont: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		got := precomp.Commit(evaluations)
+		if !got.Equal(&expected) {
+			t.Fatalf("commitment of %d evaluations is incorrect", n)
+		}
+	}
+}
+
+func TestCommitFunc(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	// The evaluations are derived from the index, as a caller iterating over leaves would.
+	get := func(i int) fr.Element {
+		var evaluation fr.Element
+		evaluation.SetUint64(uint64(i*i + 7))
+		return evaluation
+	}
+
+	for _, n := range []int{0, 1, testPrecomputeNumPoints} {
+		evaluations := make([]fr.Element, n)
+		for i := range evaluations {
+			evaluations[i] = get(i)
+		}
+		expected := precomp.Commit(evaluations)
+
+		got := precomp.CommitFunc(n, get)
+		if !got.Equal(&expected) {
+			t.Fatalf("commitment of %d lazy evaluations is incorrect", n)
+		}
+		// The test SRS is shorter than minParallelCommitLength, so also check
+		// the parallel path directly.
+		got = precomp.commitFuncParallel(n, get)
+		if !got.Equal(&expected) {
+			t.Fatalf("parallel commitment of %d lazy evaluations is incorrect", n)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for more evaluations than points")
+		}
+	}()
+	precomp.CommitFunc(testPrecomputeNumPoints+1, get)
+}
+
+// The test SRS is below minParallelCommitLength, so benchmarks use a full size one.
+var (
+	benchPrecomputeOnce sync.Once
+	benchPrecompute     *PrecomputeLagrange
+)
+
+func getBenchPrecompute() *PrecomputeLagrange {
+	benchPrecomputeOnce.Do(func() {
+		points := make([]Element, 256)
+		point := Generator
+		for i := range points {
+			points[i] = point
+			point.Add(&point, &Generator)
+		}
+		benchPrecompute = NewPrecomputeLagrange(points)
+	})
+	return benchPrecompute
+}
+
+func BenchmarkCommitParallel(b *testing.B) {
+	precomp := getBenchPrecompute()
+
+	for _, n := range []int{32, 128, 256} {
+		evaluations := make([]fr.Element, n)
+		for i := range evaluations {
+			evaluations[i].SetRandom()
+		}
+		b.Run(fmt.Sprintf("Commit/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = precomp.Commit(evaluations)
+			}
+		})
+		b.Run(fmt.Sprintf("CommitParallel/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = precomp.CommitParallel(evaluations)
+			}
+		})
+	}
+}
+
+func TestNewPrecomputeLagrangeWithWorkers(t *testing.T) {
+	points := []Element{Generator}
+	for _, maxWorkers := range []int{0, -1} {
+		if _, err := NewPrecomputeLagrangeWithWorkers(points, maxWorkers); err == nil {
+			t.Fatalf("expected an error for %d workers", maxWorkers)
+		}
+	}
+
+	limited, err := NewPrecomputeLagrangeWithWorkers(points, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !limited.Equal(*NewPrecomputeLagrange(points)) {
+		t.Fatal("limiting the number of workers changed the tables")
+	}
+}
+
+func TestMaxLength(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	if precomp.MaxLength() != testPrecomputeNumPoints {
+		t.Fatalf("expected max length %d, got %d", testPrecomputeNumPoints, precomp.MaxLength())
+	}
+	if !precomp.CanHandle(0) {
+		t.Fatal("an empty vector should be accepted")
+	}
+	if !precomp.CanHandle(testPrecomputeNumPoints) {
+		t.Fatal("a vector of exactly the max length should be accepted")
+	}
+	if precomp.CanHandle(testPrecomputeNumPoints + 1) {
+		t.Fatal("a vector longer than the max length should be rejected")
+	}
+	if precomp.CanHandle(-1) {
+		t.Fatal("a negative length should be rejected")
+	}
+
+	// Committing a vector of exactly the max length works.
+	evaluations := make([]fr.Element, precomp.MaxLength())
+	for i := range evaluations {
+		evaluations[i].SetUint64(uint64(i + 1))
+	}
+	got := precomp.Commit(evaluations)
+
+	var expected Element
+	expected.Identity()
+	for i := range evaluations {
+		var tmp Element
+		tmp.ScalarMul(&testPrecomputePoints[i], &evaluations[i])
+		expected.Add(&expected, &tmp)
+	}
+	if !got.Equal(&expected) {
+		t.Fatal("commitment of a vector of the max length is incorrect")
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	got := precomp.MemoryUsage()
+	if got != EstimatePrecomputeLagrangeMemory(testPrecomputeNumPoints) {
+		t.Fatalf("memory usage %d doesn't match the estimate %d", got, EstimatePrecomputeLagrangeMemory(testPrecomputeNumPoints))
+	}
+	// An affine point is two 32 bytes field elements.
+	expected := uint64(5*16*65535+(testPrecomputeNumPoints-5)*32*255) * 64
+	if got != expected {
+		t.Fatalf("expected %d bytes, got %d", expected, got)
+	}
+
+	if EstimatePrecomputeLagrangeMemory(0) != 0 {
+		t.Fatal("an empty SRS shouldn't use any memory")
+	}
+	if EstimatePrecomputeLagrangeMemory(2) != 2*16*65535*64 {
+		t.Fatal("a small SRS should only use 16-bit tables")
+	}
+}
+
+func TestCommitInt64(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	got := precomp.CommitInt64([]int64{-1})
+	expected := precomp.Commit([]fr.Element{fr.MinusOne()})
+	if !got.Equal(&expected) {
+		t.Fatal("committing -1 should be the same as committing q-1")
+	}
+
+	values := []int64{-5, 7, 0, math.MinInt64, math.MaxInt64, -1, 1 << 40, -(1 << 40)}
+	evaluations := make([]fr.Element, len(values))
+	for i, v := range values {
+		evaluations[i].SetBigInt(big.NewInt(v))
+	}
+	got = precomp.CommitInt64(values)
+	expected = precomp.Commit(evaluations)
+	if !got.Equal(&expected) {
+		t.Fatal("commitment to a mixed-sign vector is incorrect")
+	}
+}
+
+func BenchmarkCommitInt64(b *testing.B) {
+	precomp := getTestPrecompute()
+
+	values := make([]int64, testPrecomputeNumPoints)
+	for i := range values {
+		values[i] = int64(i) * 1_000_003
+		if i%2 == 1 {
+			values[i] = -values[i]
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = precomp.CommitInt64(values)
+	}
+}
+
+func TestDeserializePrecomputedLagrangeInvalid(t *testing.T) {
+	writeInts := func(w io.Writer, values ...int64) {
+		for _, v := range values {
+			if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// The number of tables doesn't match the number of points.
+	var buf bytes.Buffer
+	writeInts(&buf, 7, 5)
+	if _, err := DeserializePrecomputedLagrange(&buf); err == nil {
+		t.Fatal("expected an error for a wrong number of 8-bit tables")
+	}
+
+	// A negative number of points.
+	buf.Reset()
+	writeInts(&buf, -1)
+	if _, err := DeserializePrecomputedLagrange(&buf); err == nil {
+		t.Fatal("expected an error for a negative number of points")
+	}
+
+	// A number of points too large to be a real SRS.
+	buf.Reset()
+	writeInts(&buf, 1<<40, 1<<40-optimized16BitIdxs)
+	if _, err := DeserializePrecomputedLagrange(&buf); err == nil {
+		t.Fatal("expected an error for a huge number of points")
+	}
+
+	// A well formed table, but with a window size that isn't the 8-bit one.
+	table := NewLagrangeTablePoints(Generator, 2, 4)
+	buf.Reset()
+	writeInts(&buf, optimized16BitIdxs+1, 1)
+	if err := table.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DeserializePrecomputedLagrange(&buf); err == nil {
+		t.Fatal("expected an error for a table with the wrong window size")
+	}
+
+	// A table whose number of points isn't a multiple of the window size.
+	buf.Reset()
+	writeInts(&buf, 7, 3)
+	if err := (&LagrangeTablePoints{}).Deserialize(&buf); err == nil {
+		t.Fatal("expected an error for a corrupted table header")
+	}
+
+	// A truncated table.
+	buf.Reset()
+	if err := table.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	truncated := buf.Bytes()[:buf.Len()-1]
+	if err := (&LagrangeTablePoints{}).Deserialize(bytes.NewReader(truncated)); err == nil {
+		t.Fatal("expected an error for a truncated table")
+	}
+
+	// The same table round-trips when no particular shape is expected.
+	buf.Reset()
+	if err := table.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got LagrangeTablePoints
+	if err := got.Deserialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(*table) {
+		t.Fatal("table is not equal after (de)serialization")
+	}
+}
+
+func TestPrecomputeLagrangeWriteToLoad(t *testing.T) {
+	precomp := getTestPrecompute()
+
+	var buf bytes.Buffer
+	n, err := precomp.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("WriteTo reported %d bytes, but wrote %d", n, buf.Len())
+	}
+	encoded := buf.Bytes()
+
+	got, err := LoadPrecomputeLagrange(bytes.NewReader(encoded), testPrecomputeNumPoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(*precomp) {
+		t.Fatal("precomputed tables are not equal after WriteTo/LoadPrecomputeLagrange")
+	}
+
+	if _, err := LoadPrecomputeLagrange(bytes.NewReader(encoded), testPrecomputeNumPoints+1); err == nil {
+		t.Fatal("expected an error for a wrong number of points")
+	}
+
+	corrupted := append([]byte{}, encoded...)
+	corrupted[0] ^= 1
+	if _, err := LoadPrecomputeLagrange(bytes.NewReader(corrupted), testPrecomputeNumPoints); err == nil {
+		t.Fatal("expected an error for a wrong magic")
+	}
+
+	corrupted = append([]byte{}, encoded...)
+	corrupted[len(precomputeLagrangeMagic)]++
+	if _, err := LoadPrecomputeLagrange(bytes.NewReader(corrupted), testPrecomputeNumPoints); err == nil {
+		t.Fatal("expected an error for an unsupported version")
+	}
+
+	if _, err := LoadPrecomputeLagrange(bytes.NewReader(encoded[:len(encoded)/2]), testPrecomputeNumPoints); err == nil {
+		t.Fatal("expected an error for truncated input")
+	}
+
+	// A crafted header claiming a huge number of points is rejected before allocating tables.
+	var crafted bytes.Buffer
+	crafted.Write(precomputeLagrangeMagic[:])
+	if err := binary.Write(&crafted, binary.LittleEndian, precomputeLagrangeVersion); err != nil {
+		t.Fatal(err)
+	}
+	if err := binary.Write(&crafted, binary.LittleEndian, []int64{1 << 40, 1<<40 - optimized16BitIdxs}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrecomputeLagrange(&crafted, testPrecomputeNumPoints); err == nil {
+		t.Fatal("expected an error for a crafted number of points")
+	}
+
+	// A point that is not on the curve is only detected by Validate.
+	if err := got.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	corrupted = append([]byte{}, encoded...)
+	corrupted[len(corrupted)-1] ^= 1
+	invalid, err := LoadPrecomputeLagrange(bytes.NewReader(corrupted), testPrecomputeNumPoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := invalid.Validate(); err == nil {
+		t.Fatal("expected an error for a point not on the curve")
+	}
+}
+
+func TestNewLagrangeTablePoints(t *testing.T) {
+	// A power of two base builds the rows by doubling, any other base by scalar multiplication.
+	for _, base := range []int{4, 3} {
+		const numRows = 5
+		table := NewLagrangeTablePoints(Generator, numRows, base)
+
+		scale := uint64(1)
+		for row := 0; row < numRows; row++ {
+			for value := 1; value < base; value++ {
+				var scalar fr.Element
+				scalar.SetUint64(scale * uint64(value))
+				var expected Element
+				expected.ScalarMul(&Generator, &scalar)
+
+				var got Element
+				got.inner.FromAffine(table.point(row, uint16(value)))
+				if !got.Equal(&expected) {
+					t.Fatalf("base %d: entry (%d, %d) is incorrect", base, row, value)
+				}
+			}
+			scale *= uint64(base)
+		}
+	}
+}
+
+const testPrecomputeNumPoints = 16
+
+var (
+	testPrecomputeOnce   sync.Once
+	testPrecomputePoints []Element
+	testPrecompute       *PrecomputeLagrange
+)
+
+// getTestPrecompute returns a PrecomputeLagrange shared between tests, since
+// building the precomputed tables is expensive.
+func getTestPrecompute() *PrecomputeLagrange {
+	testPrecomputeOnce.Do(func() {
+		testPrecomputePoints = make([]Element, testPrecomputeNumPoints)
+		point := Generator
+		for i := range testPrecomputePoints {
+			testPrecomputePoints[i] = point
+			point.Add(&point, &Generator)
+		}
+		testPrecompute = NewPrecomputeLagrange(testPrecomputePoints)
+	})
+	return testPrecompute
+}
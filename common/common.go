@@ -18,28 +18,16 @@ const POLY_DEGREE = 256
 // TODO the first one we can use the bls package for
 // TODO The second we _could_ just multiply on each iteration, (depends on how readable it is)
 func PowersOf(x fr.Element, degree int) []fr.Element {
-	result := make([]fr.Element, degree)
-	result[0] = fr.One()
-
-	for i := 1; i < degree; i++ {
-		result[i].Mul(&result[i-1], &x)
-	}
-
-	return result
+	return fr.Powers(x, degree)
 }
 
 func ReadPoint(r io.Reader) *banderwagon.Element {
 	var x = make([]byte, 32)
-	n, err := r.Read(x)
-	if err != nil {
+	if _, err := io.ReadFull(r, x); err != nil {
 		panic("error reading bytes")
 	}
-	if n != 32 {
-		panic("did not read enough bytes")
-	}
 	var p = &banderwagon.Element{}
-	err = p.SetBytes(x)
-	if err != nil {
+	if err := p.SetBytes(x); err != nil {
 		panic("could not deserialize point")
 	}
 	return p
@@ -47,17 +35,10 @@ func ReadPoint(r io.Reader) *banderwagon.Element {
 
 func ReadScalar(r io.Reader) *fr.Element {
 	var x = make([]byte, 32)
-	n, err := r.Read(x)
-	if err != nil {
+	if _, err := io.ReadFull(r, x); err != nil {
 		panic("error reading bytes")
 	}
-	if n != 32 {
-		panic("did not read enough bytes")
-	}
 	var scalar = &fr.Element{}
 	scalar.SetBytesLE(x)
-	if err != nil {
-		panic("could not deserialize point")
-	}
 	return scalar
 }
@@ -12,19 +12,38 @@ import (
 /// See: Fiat-Shamir
 type Transcript struct {
 	state hash.Hash
+	// label the transcript was created with, kept so it can be reset
+	label string
 }
 
+// NewTranscript creates a transcript that uses sha256, which is the hash
+// used by the verkle trie specification. The label is absorbed before anything
+// else, and separates the challenges of different protocols.
 func NewTranscript(label string) *Transcript {
-	digest := sha256.New()
+	return NewTranscriptWithHash(label, sha256.New)
+}
+
+// NewTranscriptWithHash creates a transcript that uses the hash returned by newHash.
+// Proofs are only verifiable with a transcript that uses the same hash as the prover's.
+func NewTranscriptWithHash(label string, newHash func() hash.Hash) *Transcript {
+	digest := newHash()
 	digest.Write([]byte(label))
 
 	transcript := &Transcript{
 		state: digest,
+		label: label,
 	}
 
 	return transcript
 }
 
+// Reset returns the transcript to the state it had right after being created,
+// so that it can be reused, e.g. from a sync.Pool, instead of allocating a new one.
+func (t *Transcript) Reset() {
+	t.state.Reset()
+	t.state.Write([]byte(t.label))
+}
+
 func (t *Transcript) AppendMessage(message []byte, label string) {
 	t.state.Write([]byte(label))
 	t.state.Write(message)
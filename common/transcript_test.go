@@ -1,6 +1,8 @@
 package common
 
 import (
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"testing"
 
@@ -80,3 +82,47 @@ func TestVector4(t *testing.T) {
 		panic("computed challenge scalar is incorrect")
 	}
 }
+
+func TestReset(t *testing.T) {
+	tr := NewTranscript("simple_protocol")
+	gen := banderwagon.Generator
+	tr.AppendPoint(&gen, "generator")
+	tr.DomainSep("separate me")
+	tr.ChallengeScalar("simple_challenge")
+
+	tr.Reset()
+
+	// After a reset, the transcript should behave like a fresh one; see TestVector2
+	five := fr.Element{}
+	five.SetUint64(5)
+
+	tr.AppendScalar(&five, "five")
+	tr.AppendScalar(&five, "five again")
+
+	challenge := tr.ChallengeScalar("simple_challenge")
+	c_bytes := challenge.BytesLE()
+
+	expected := "498732b694a8ae1622d4a9347535be589e4aee6999ffc0181d13fe9e4d037b0b"
+	got := hex.EncodeToString(c_bytes[:])
+	if expected != got {
+		panic("a reset transcript should compute the same challenges as a fresh transcript")
+	}
+}
+
+func TestNewTranscriptWithHash(t *testing.T) {
+	five := fr.Element{}
+	five.SetUint64(5)
+
+	challenge := func(tr *Transcript) fr.Element {
+		tr.AppendScalar(&five, "five")
+		return tr.ChallengeScalar("simple_challenge")
+	}
+
+	expected := challenge(NewTranscript("simple_protocol"))
+	if got := challenge(NewTranscriptWithHash("simple_protocol", sha256.New)); got != expected {
+		t.Fatal("a sha256 transcript should match the default one")
+	}
+	if got := challenge(NewTranscriptWithHash("simple_protocol", sha512.New512_256)); got == expected {
+		t.Fatal("transcripts with different hashes should yield different challenges")
+	}
+}
@@ -20,17 +20,23 @@ type PrecomputedWeights struct {
 }
 
 func NewPrecomputedWeights() *PrecomputedWeights {
+	return newPrecomputedWeights(DOMAIN_SIZE)
+}
+
+// newPrecomputedWeights computes the weights for the domain [0, domainSize).
+// Only NewIPASettingsWithSRS uses a domain size other than DOMAIN_SIZE.
+func newPrecomputedWeights(domainSize uint64) *PrecomputedWeights {
 	// Imagine we have two arrays of the same length and we concatenate them together
 	// This is how we will store the A'(x_i) and 1/A'(x_i)
 	// This midpoint variable is used to compute the offset that we need
 	// to place 1/A'(x_i)
-	midpoint := uint64(DOMAIN_SIZE)
+	midpoint := domainSize
 
-	// Note there are DOMAIN_SIZE number of weights, but we are also storing their inverses
+	// Note there are domainSize number of weights, but we are also storing their inverses
 	// so we need double the amount of space
 	barycentricWeights := make([]fr.Element, midpoint*2)
 	for i := uint64(0); i < midpoint; i++ {
-		weight := computeBarycentricWeightForElement(i)
+		weight := computeBarycentricWeightForElement(i, domainSize)
 
 		var invWeight fr.Element
 		invWeight.Inverse(&weight)
@@ -41,9 +47,9 @@ func NewPrecomputedWeights() *PrecomputedWeights {
 
 	// Computing 1/k and -1/k for k \in [0, 255]
 	// Note that since we cannot do 1/0, we have one less element
-	midpoint = DOMAIN_SIZE - 1
+	midpoint = domainSize - 1
 	invertedDomain := make([]fr.Element, midpoint*2)
-	for i := uint64(1); i < DOMAIN_SIZE; i++ {
+	for i := uint64(1); i < domainSize; i++ {
 		var k fr.Element
 		k.SetUint64(i)
 		k.Inverse(&k)
@@ -66,10 +72,10 @@ func NewPrecomputedWeights() *PrecomputedWeights {
 // computes A'(x_j) where x_j must be an element in the domain
 // This is computed as the product of x_j - x_i where x_i is an element in the domain
 // and x_i is not equal to x_j
-func computeBarycentricWeightForElement(element uint64) fr.Element {
+func computeBarycentricWeightForElement(element uint64, domainSize uint64) fr.Element {
 	// let domain_element_fr = Fr::from(domain_element as u128);
-	if element > DOMAIN_SIZE {
-		panic(fmt.Sprintf("the domain is [0,255], %d is not in the domain", element))
+	if element > domainSize {
+		panic(fmt.Sprintf("the domain is [0,%d], %d is not in the domain", domainSize-1, element))
 	}
 
 	var domain_element_fr fr.Element
@@ -77,7 +83,7 @@ func computeBarycentricWeightForElement(element uint64) fr.Element {
 
 	total := fr.One()
 
-	for i := uint64(0); i < DOMAIN_SIZE; i++ {
+	for i := uint64(0); i < domainSize; i++ {
 		if i == element {
 			continue
 		}
@@ -100,10 +106,11 @@ func computeBarycentricWeightForElement(element uint64) fr.Element {
 // Note that `z` should not be in the domain
 // This can also be seen as the lagrange coefficients L_i(point)
 func (preComp *PrecomputedWeights) ComputeBarycentricCoefficients(point fr.Element) []fr.Element {
+	domainSize := preComp.domainSize()
 
 	// Compute A(x_i) * point - x_i
-	lagrangeEvals := make([]fr.Element, DOMAIN_SIZE)
-	for i := uint64(0); i < DOMAIN_SIZE; i++ {
+	lagrangeEvals := make([]fr.Element, domainSize)
+	for i := uint64(0); i < domainSize; i++ {
 		weight := preComp.barycentricWeights[i]
 
 		var i_fr fr.Element
@@ -113,7 +120,7 @@ func (preComp *PrecomputedWeights) ComputeBarycentricCoefficients(point fr.Eleme
 	}
 
 	totalProd := fr.One()
-	for i := uint64(0); i < DOMAIN_SIZE; i++ {
+	for i := uint64(0); i < domainSize; i++ {
 		var i_fr fr.Element
 		i_fr.SetUint64(i)
 
@@ -123,7 +130,7 @@ func (preComp *PrecomputedWeights) ComputeBarycentricCoefficients(point fr.Eleme
 	}
 
 	lagrangeEvals = fr.BatchInvert(lagrangeEvals)
-	for i := uint64(0); i < DOMAIN_SIZE; i++ {
+	for i := uint64(0); i < domainSize; i++ {
 		lagrangeEvals[i].Mul(&lagrangeEvals[i], &totalProd)
 	}
 
@@ -134,11 +141,12 @@ func (preComp *PrecomputedWeights) ComputeBarycentricCoefficients(point fr.Eleme
 // pointer and clear the buffer each time
 // computes f(x) - f(x_i) / x - x_i where x_i is an element in the domain
 func (preComp *PrecomputedWeights) DivideOnDomain(index uint8, f []fr.Element) []fr.Element {
-	quotient := make([]fr.Element, DOMAIN_SIZE)
+	domainSize := int(preComp.domainSize())
+	quotient := make([]fr.Element, domainSize)
 
 	y := f[index]
 
-	for i := 0; i < DOMAIN_SIZE; i++ {
+	for i := 0; i < domainSize; i++ {
 		if i != int(index) {
 			den := i - int(index)
 			absDen, is_neg := absInt(den)
@@ -159,6 +167,11 @@ func (preComp *PrecomputedWeights) DivideOnDomain(index uint8, f []fr.Element) [
 	return quotient
 }
 
+// domainSize returns the number of elements of the domain the weights were computed for.
+func (preComp *PrecomputedWeights) domainSize() uint64 {
+	return uint64(len(preComp.barycentricWeights) / 2)
+}
+
 func (preComp *PrecomputedWeights) getInvertedElement(element int, is_neg bool) fr.Element {
 	index := element - 1
 
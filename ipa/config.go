@@ -1,13 +1,13 @@
 package ipa
 
 import (
-	"crypto/sha256"
-	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"math"
 	"runtime"
+	"strings"
 
-	"github.com/crate-crypto/go-ipa/bandersnatch/fp"
+	"github.com/crate-crypto/go-ipa/bandersnatch"
 	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
 	"github.com/crate-crypto/go-ipa/banderwagon"
 	"github.com/crate-crypto/go-ipa/common"
@@ -44,6 +44,56 @@ func NewIPASettingsWithSRSPrecomp(srs_precomp *SRSPrecompPoints) *IPAConfig {
 	}
 }
 
+// NewIPASettingsWithSRS creates the settings for proving and verifying with the given SRS
+// instead of the one in the verkle trie specification. The vectors committed to must have
+// as many elements as the SRS, and are evaluated over the domain [0, len(srs)).
+//
+// The SRS must have a power of two length and every point must be a distinct, non-identity
+// element of the subgroup. Q is always the generator, so it can't be part of the SRS.
+// Proofs built with a SRS of a length other than common.POLY_DEGREE can't be serialised
+// with IPAProof.MarshalBinary, which expects the canonical number of rounds.
+func NewIPASettingsWithSRS(srs []banderwagon.Element) (*IPAConfig, error) {
+	n := len(srs)
+	if n == 0 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("SRS length must be a power of two, got %d", n)
+	}
+	if err := banderwagon.ValidateBasis(srs); err != nil {
+		return nil, fmt.Errorf("invalid SRS: %s", err)
+	}
+	Q := banderwagon.Generator
+	for i := range srs {
+		if srs[i].Equal(&Q) {
+			return nil, fmt.Errorf("invalid SRS: point %d is the generator", i)
+		}
+	}
+
+	srsCopy := make([]banderwagon.Element, n)
+	copy(srsCopy, srs)
+	return &IPAConfig{
+		SRSPrecompPoints: &SRSPrecompPoints{
+			SRS:        srsCopy,
+			Q:          Q,
+			PrecompLag: banderwagon.NewPrecomputeLagrange(srsCopy),
+		},
+		PrecomputedWeights: newPrecomputedWeights(uint64(n)),
+		num_ipa_rounds:     compute_num_rounds(uint32(n)),
+	}, nil
+}
+
+// SRS returns a copy of the points used to commit to vectors. Together with SRSAffine,
+// this is the supported way of reading the SRS, e.g. to build an MSM engine from it;
+// SRSPrecompPoints is kept for backwards compatibility.
+func (ic *IPAConfig) SRS() []banderwagon.Element {
+	srs := make([]banderwagon.Element, len(ic.SRSPrecompPoints.SRS))
+	copy(srs, ic.SRSPrecompPoints.SRS)
+	return srs
+}
+
+// SRSAffine returns the points of the SRS in affine form. See SRS.
+func (ic *IPAConfig) SRSAffine() []bandersnatch.PointAffine {
+	return banderwagon.BatchToAffine(ic.SRSPrecompPoints.SRS)
+}
+
 func multiScalar(points []banderwagon.Element, scalars []fr.Element) banderwagon.Element {
 	var result banderwagon.Element
 	result.Identity()
@@ -75,19 +125,7 @@ func commit(group_elements []banderwagon.Element, polynomial []fr.Element) bande
 // Computes the inner product of a and b
 // panics if len(a) != len(b)
 func InnerProd(a []fr.Element, b []fr.Element) fr.Element {
-	if len(a) != len(b) {
-		panic("two vectors must have the same lengths")
-	}
-
-	result := fr.Zero()
-	for i := 0; i < len(a); i++ {
-		var tmp fr.Element
-
-		tmp.Mul(&a[i], &b[i])
-		result.Add(&result, &tmp)
-	}
-
-	return result
+	return fr.InnerProduct(a, b)
 }
 
 // Computes c[i] =a[i] + b[i] * x
@@ -124,6 +162,24 @@ func foldPoints(a []banderwagon.Element, b []banderwagon.Element, x fr.Element)
 	return result
 }
 
+// FoldScalars folds a vector in half with the challenge x, as done on every round of
+// the IPA: the result is a_lo + x * a_hi, where a_lo and a_hi are the two halves of a.
+// The prover folds `a` with x and `b` with x^-1.
+// panics if the length of a is not even
+func FoldScalars(a []fr.Element, x fr.Element) []fr.Element {
+	a_lo, a_hi := splitScalars(a)
+	return foldScalars(a_lo, a_hi, x)
+}
+
+// FoldPoints folds a basis in half with the inverse of the round challenge, as done on
+// every round of the IPA: the result is G_lo + xInv * G_hi, where G_lo and G_hi are the
+// two halves of G.
+// panics if the length of G is not even
+func FoldPoints(G []banderwagon.Element, xInv fr.Element) []banderwagon.Element {
+	G_lo, G_hi := splitPoints(G)
+	return foldPoints(G_lo, G_hi, xInv)
+}
+
 // Splits a slice of scalars into two slices of equal length
 // Eg [S1,S2,S3,S4] becomes [S1,S2] , [S3,S4]
 // panics if the number of scalars is not even
@@ -174,39 +230,33 @@ func compute_num_rounds(vector_size uint32) uint32 {
 	return uint32(res)
 }
 
+// GenerateRandomPoints generates the SRS of the verkle trie specification.
+// See banderwagon.GenerateBasisPoints.
 func GenerateRandomPoints(numPoints uint64) []banderwagon.Element {
-	seed := "eth_verkle_oct_2021" // incase it changes or needs updating, we can use eth_verkle_month_year
-
-	points := []banderwagon.Element{}
-
-	var increment uint64 = 0
-
-	for uint64(len(points)) != numPoints {
-
-		digest := sha256.New()
-		digest.Write([]byte(seed))
-
-		b := make([]byte, 8)
-		binary.BigEndian.PutUint64(b, increment)
-		digest.Write(b)
-
-		hash := digest.Sum(nil)
-
-		var x fp.Element
-		x.SetBytes(hash)
-
-		increment++
+	return banderwagon.GenerateBasisPoints(banderwagon.VerkleBasisSeed, numPoints)
+}
 
-		x_as_bytes := x.Bytes()
-		var point_found banderwagon.Element
-		err := point_found.SetBytes(x_as_bytes[:])
+// ParseSRSHex decodes a list of hex-encoded SRS points, one point per line.
+// Each line must be the 32-byte serialised form of a banderwagon element,
+// as returned by Element.Bytes(), optionally prefixed by "0x".
+// Every point is checked to be on the curve and in the correct subgroup.
+func ParseSRSHex(hexLines []string) ([]banderwagon.Element, error) {
+	points := make([]banderwagon.Element, len(hexLines))
+	for i, line := range hexLines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "0x")
+
+		bytes, err := hex.DecodeString(line)
 		if err != nil {
-			// This point is not in the correct subgroup or on the curve
-			continue
+			return nil, fmt.Errorf("decoding hex of point at line %d: %s", i, err)
+		}
+		if len(bytes) != 32 {
+			return nil, fmt.Errorf("point at line %d has %d bytes, expected 32", i, len(bytes))
+		}
+		if err := points[i].SetBytes(bytes); err != nil {
+			return nil, fmt.Errorf("deserializing point at line %d: %s", i, err)
 		}
-		points = append(points, point_found)
-
 	}
 
-	return points
+	return points, nil
 }
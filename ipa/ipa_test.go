@@ -3,7 +3,13 @@ package ipa
 import (
 	"bytes"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
@@ -103,6 +109,394 @@ func TestIPAConsistencySimpleProof(t *testing.T) {
 	}
 }
 
+func TestIPAProofWithChallenges(t *testing.T) {
+	ipaConf := getTestIPAConfig()
+
+	var point fr.Element
+	point.SetUint64(123456789)
+
+	poly := test_helper.TestPoly256(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14)
+	comm := ipaConf.Commit(poly)
+
+	prover_transcript := common.NewTranscript("ipa")
+	proof := CreateIPAProof(prover_transcript, ipaConf, comm, poly, point)
+
+	lagrange_coeffs := ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(point)
+	inner_product := InnerProd(poly, lagrange_coeffs)
+
+	// Replay the verifier transcript to recover the challenges.
+	transcript := common.NewTranscript("ipa")
+	transcript.DomainSep("ipa")
+	transcript.AppendPoint(&comm, "C")
+	transcript.AppendScalar(&point, "input point")
+	transcript.AppendScalar(&inner_product, "output point")
+	w := transcript.ChallengeScalar("w")
+	challenges := generateChallenges(transcript, &proof)
+
+	if !CheckIPAProofWithChallenges(ipaConf, comm, proof, point, inner_product, w, challenges) {
+		t.Fatal("proof should verify with the correct challenges")
+	}
+
+	one := fr.One()
+	wrongChallenges := append([]fr.Element{}, challenges...)
+	wrongChallenges[3].Add(&wrongChallenges[3], &one)
+	if CheckIPAProofWithChallenges(ipaConf, comm, proof, point, inner_product, w, wrongChallenges) {
+		t.Fatal("proof should not verify with a wrong round challenge")
+	}
+
+	var wrongW fr.Element
+	wrongW.Add(&w, &one)
+	if CheckIPAProofWithChallenges(ipaConf, comm, proof, point, inner_product, wrongW, challenges) {
+		t.Fatal("proof should not verify with a wrong `w` challenge")
+	}
+}
+
+func TestIPAProofRounds(t *testing.T) {
+	if ipaProofRounds != int(compute_num_rounds(DOMAIN_SIZE)) {
+		t.Fatalf("ipaProofRounds is %d, but the domain needs %d rounds", ipaProofRounds, compute_num_rounds(DOMAIN_SIZE))
+	}
+}
+
+func TestIPAProofBinaryEncoding(t *testing.T) {
+	var identity banderwagon.Element
+	identity.Identity()
+
+	proof := IPAProof{A_scalar: fr.One()}
+	for i := 0; i < ipaProofRounds; i++ {
+		proof.L = append(proof.L, banderwagon.Generator)
+		proof.R = append(proof.R, identity)
+	}
+
+	// Pin the wire format: the L points, the R points and the scalar in little endian.
+	generatorHex := "4a2c7486fd924882bf02c6908de395122843e3e05264d7991e18e7985dad51e9"
+	identityHex := strings.Repeat("00", 32)
+	oneHex := "01" + strings.Repeat("00", 31)
+	golden := strings.Repeat(generatorHex, ipaProofRounds) + strings.Repeat(identityHex, ipaProofRounds) + oneHex
+
+	encoded, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(encoded) != golden {
+		t.Fatalf("unexpected encoding %x", encoded)
+	}
+
+	var got IPAProof
+	if err := got.UnmarshalBinary(encoded); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(proof) {
+		t.Fatal("proof is not equal after (de)serialization")
+	}
+
+	if err := got.UnmarshalBinary(append(append([]byte{}, encoded...), 0)); err == nil {
+		t.Fatal("expected an error for trailing bytes")
+	}
+	if err := got.UnmarshalBinary(encoded[:len(encoded)-1]); err == nil {
+		t.Fatal("expected an error for truncated input")
+	}
+
+	invalidPoint := append([]byte{}, encoded...)
+	for i := 32; i < 64; i++ {
+		invalidPoint[i] = 0xff
+	}
+	if err := got.UnmarshalBinary(invalidPoint); err == nil {
+		t.Fatal("expected an error for an invalid point")
+	}
+
+	nonCanonicalScalar := append([]byte{}, encoded...)
+	modulus := fr.Modulus().Bytes()
+	for i := range modulus {
+		nonCanonicalScalar[len(nonCanonicalScalar)-1-i] = modulus[i]
+	}
+	if err := got.UnmarshalBinary(nonCanonicalScalar); err == nil {
+		t.Fatal("expected an error for a scalar that isn't reduced")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected Read to panic for a scalar that isn't reduced")
+			}
+		}()
+		got.Read(bytes.NewReader(nonCanonicalScalar))
+	}()
+
+	proof.R = proof.R[1:]
+	if _, err := proof.MarshalBinary(); err == nil {
+		t.Fatal("expected an error for L and R of different sizes")
+	}
+	proof.L = proof.L[1:]
+	if _, err := proof.MarshalBinary(); err == nil {
+		t.Fatal("expected an error for a wrong number of rounds")
+	}
+}
+
+func TestDeserializeIPAProof(t *testing.T) {
+	proof := IPAProof{A_scalar: fr.One()}
+	for i := 0; i < ipaProofRounds; i++ {
+		proof.L = append(proof.L, banderwagon.Generator)
+		proof.R = append(proof.R, banderwagon.Generator)
+	}
+	var buf bytes.Buffer
+	proof.Write(&buf)
+	encoded := buf.Bytes()
+
+	got, err := DeserializeIPAProof(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(proof) {
+		t.Fatal("proof is not equal after Write/DeserializeIPAProof")
+	}
+
+	// Whatever the input claims, no more than IPAProofSize bytes are read.
+	reader := bytes.NewReader(append(append([]byte{}, encoded...), 0xff))
+	if _, err := DeserializeIPAProof(reader); err != nil || reader.Len() != 1 {
+		t.Fatal("expected exactly IPAProofSize bytes to be read")
+	}
+
+	for _, n := range []int{0, 31, len(encoded) - 1} {
+		_, err := DeserializeIPAProof(bytes.NewReader(encoded[:n]))
+		if !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			t.Fatalf("expected an EOF error for %d bytes, got %v", n, err)
+		}
+		if !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("expected ErrInvalidProof for %d bytes, got %v", n, err)
+		}
+	}
+
+	// An x co-ordinate that isn't reduced is not a valid encoding.
+	invalidPoint := append([]byte{}, encoded...)
+	for i := 32; i < 64; i++ {
+		invalidPoint[i] = 0xff
+	}
+	_, err = DeserializeIPAProof(bytes.NewReader(invalidPoint))
+	if !errors.Is(err, banderwagon.ErrInvalidEncoding) || !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected ErrInvalidEncoding and ErrInvalidProof, got %v", err)
+	}
+
+	// A final scalar that isn't reduced.
+	nonCanonicalScalar := append([]byte{}, encoded...)
+	modulus := fr.Modulus().Bytes()
+	for i := range modulus {
+		nonCanonicalScalar[len(nonCanonicalScalar)-1-i] = modulus[i]
+	}
+	if _, err := DeserializeIPAProof(bytes.NewReader(nonCanonicalScalar)); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected ErrInvalidProof for a scalar that isn't reduced, got %v", err)
+	}
+
+	// Trailing or missing bytes passed directly to UnmarshalBinary.
+	for _, data := range [][]byte{encoded[:len(encoded)-1], append(append([]byte{}, encoded...), 0)} {
+		if err := got.UnmarshalBinary(data); !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("expected ErrInvalidProof for %d bytes, got %v", len(data), err)
+		}
+	}
+}
+
+var _ Transcript = (*common.Transcript)(nil)
+
+func TestIPAProofPluggableTranscript(t *testing.T) {
+	ipaConf := getTestIPAConfig()
+
+	var point fr.Element
+	point.SetUint64(123456789)
+
+	poly := test_helper.TestPoly256(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14)
+	comm := ipaConf.Commit(poly)
+
+	lagrange_coeffs := ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(point)
+	inner_product := InnerProd(poly, lagrange_coeffs)
+
+	proof := CreateIPAProof(common.NewTranscriptWithHash("ipa", sha512.New512_256), ipaConf, comm, poly, point)
+
+	if !CheckIPAProof(common.NewTranscriptWithHash("ipa", sha512.New512_256), ipaConf, comm, proof, point, inner_product) {
+		t.Fatal("proof should verify with the same transcript hash")
+	}
+	if CheckIPAProof(common.NewTranscript("ipa"), ipaConf, comm, proof, point, inner_product) {
+		t.Fatal("proof should not verify with a different transcript hash")
+	}
+}
+
+func TestIPAProofDomainSeparation(t *testing.T) {
+	ipaConf := getTestIPAConfig()
+
+	var point fr.Element
+	point.SetUint64(123456789)
+	poly := test_helper.TestPoly256(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14)
+	comm := ipaConf.Commit(poly)
+	lagrange_coeffs := ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(point)
+	inner_product := InnerProd(poly, lagrange_coeffs)
+
+	proof := CreateIPAProof(common.NewTranscript("A"), ipaConf, comm, poly, point)
+	if !CheckIPAProof(common.NewTranscript("A"), ipaConf, comm, proof, point, inner_product) {
+		t.Fatal("proof should verify under the domain it was created with")
+	}
+	if CheckIPAProof(common.NewTranscript("B"), ipaConf, comm, proof, point, inner_product) {
+		t.Fatal("proof created under domain A should not verify under domain B")
+	}
+}
+
+func TestFoldScalars(t *testing.T) {
+	a := test_helper.TestPoly256(1, 2, 3, 4)[:4]
+	var x fr.Element
+	x.SetUint64(10)
+
+	// [1, 2] + 10 * [3, 4]
+	got := FoldScalars(a, x)
+	expected := test_helper.TestPoly256(31, 42)[:2]
+	for i := range expected {
+		if !got[i].Equal(&expected[i]) {
+			t.Fatalf("folded scalar %d is incorrect", i)
+		}
+	}
+}
+
+// TestFoldsReproduceProof checks that folding `a` and the SRS with the challenges of
+// a proof gives the final scalar of the proof and the folded generator.
+func TestFoldsReproduceProof(t *testing.T) {
+	ipaConf := getTestIPAConfig()
+
+	var point fr.Element
+	point.SetUint64(123456789)
+
+	poly := test_helper.TestPoly256(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14)
+	comm := ipaConf.Commit(poly)
+	proof := CreateIPAProof(common.NewTranscript("ipa"), ipaConf, comm, poly, point)
+
+	inner_product := InnerProd(poly, ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(point))
+	transcript := common.NewTranscript("ipa")
+	transcript.DomainSep("ipa")
+	transcript.AppendPoint(&comm, "C")
+	transcript.AppendScalar(&point, "input point")
+	transcript.AppendScalar(&inner_product, "output point")
+	transcript.ChallengeScalar("w")
+	challenges := generateChallenges(transcript, &proof)
+
+	a := poly
+	G := ipaConf.SRSPrecompPoints.SRS
+	for _, x := range challenges {
+		var xInv fr.Element
+		xInv.Inverse(&x)
+		a = FoldScalars(a, x)
+		G = FoldPoints(G, xInv)
+	}
+
+	if len(a) != 1 || !a[0].Equal(&proof.A_scalar) {
+		t.Fatal("folding `a` should give the final scalar of the proof")
+	}
+	expected := FoldGenerators(ipaConf, challenges)
+	if len(G) != 1 || !G[0].Equal(&expected) {
+		t.Fatal("folding the SRS should give the folded generator")
+	}
+}
+
+func TestFoldGenerators(t *testing.T) {
+	ipaConf := getTestIPAConfig()
+
+	var point fr.Element
+	point.SetUint64(2101)
+
+	poly := test_helper.TestPoly256(1, 2, 3, 4, 5, 6, 7, 8)
+	comm := ipaConf.Commit(poly)
+
+	proof := CreateIPAProof(common.NewTranscript("ipa"), ipaConf, comm, poly, point)
+
+	lagrange_coeffs := ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(point)
+	inner_product := InnerProd(poly, lagrange_coeffs)
+
+	// Replay the verifier transcript to recover the challenges.
+	transcript := common.NewTranscript("ipa")
+	transcript.DomainSep("ipa")
+	transcript.AppendPoint(&comm, "C")
+	transcript.AppendScalar(&point, "input point")
+	transcript.AppendScalar(&inner_product, "output point")
+	transcript.ChallengeScalar("w")
+	challenges := generateChallenges(transcript, &proof)
+
+	// Fold the SRS the same way the prover does.
+	basis := ipaConf.SRSPrecompPoints.SRS
+	for _, x := range challenges {
+		var xInv fr.Element
+		xInv.Inverse(&x)
+
+		G_L, G_R := splitPoints(basis)
+		basis = foldPoints(G_L, G_R, xInv)
+	}
+	if len(basis) != 1 {
+		t.Fatalf("the folded basis should have a single element, got %d", len(basis))
+	}
+
+	got := FoldGenerators(ipaConf, challenges)
+	if !got.Equal(&basis[0]) {
+		t.Fatal("folded generator does not match the one computed by the prover")
+	}
+}
+
+// BenchmarkCreateIPAProof attributes the proving time to each of its phases.
+func BenchmarkCreateIPAProof(b *testing.B) {
+	ipaConf := getTestIPAConfig()
+
+	var point fr.Element
+	point.SetUint64(123456789)
+	poly := test_helper.TestPoly256(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14)
+	comm := ipaConf.Commit(poly)
+
+	b.Run("full", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			transcript := common.NewTranscript("ipa")
+			_ = CreateIPAProof(transcript, ipaConf, comm, poly, point)
+		}
+	})
+
+	b.Run("setup", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			transcript := common.NewTranscript("ipa")
+			coeffs := ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(point)
+			inner_prod := InnerProd(poly, coeffs)
+			transcript.AppendPoint(&comm, "C")
+			transcript.AppendScalar(&point, "input point")
+			transcript.AppendScalar(&inner_prod, "output point")
+			w := transcript.ChallengeScalar("w")
+			var q banderwagon.Element
+			q.ScalarMul(&ipaConf.SRSPrecompPoints.Q, &w)
+		}
+	})
+
+	// Collect the prover state at the beginning of each round.
+	q := ipaConf.SRSPrecompPoints.Q
+	states := make([]ipaProverState, ipaConf.num_ipa_rounds+1)
+	states[0] = ipaProverState{
+		a:     poly,
+		b:     ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(point),
+		basis: ipaConf.SRSPrecompPoints.SRS,
+	}
+	transcript := common.NewTranscript("ipa")
+	for round := 0; round < int(ipaConf.num_ipa_rounds); round++ {
+		states[round+1] = states[round]
+		states[round+1].foldRound(transcript, &q)
+	}
+
+	for round := 0; round < int(ipaConf.num_ipa_rounds); round++ {
+		round := round
+		b.Run(fmt.Sprintf("foldRound %d", round), func(b *testing.B) {
+			transcript := common.NewTranscript("ipa")
+			for i := 0; i < b.N; i++ {
+				state := states[round]
+				state.foldRound(transcript, &q)
+			}
+		})
+	}
+
+	b.Run("finalize", func(b *testing.B) {
+		L := make([]banderwagon.Element, ipaConf.num_ipa_rounds)
+		R := make([]banderwagon.Element, ipaConf.num_ipa_rounds)
+		for i := 0; i < b.N; i++ {
+			state := states[ipaConf.num_ipa_rounds]
+			_ = state.finalize(L, R)
+		}
+	})
+}
+
 func TestBasicInnerProduct(t *testing.T) {
 	var a []fr.Element
 	for i := 0; i < 10; i++ {
@@ -216,6 +610,119 @@ func TestCRSGeneration(t *testing.T) {
 	}
 }
 
+func TestSRSAccessors(t *testing.T) {
+	ipaConf := getTestIPAConfig()
+
+	srs := ipaConf.SRS()
+	srsAffine := ipaConf.SRSAffine()
+	if len(srs) != len(ipaConf.SRSPrecompPoints.SRS) || len(srsAffine) != len(srs) {
+		t.Fatal("unexpected SRS length")
+	}
+	for i := range srs {
+		if !srs[i].Equal(&ipaConf.SRSPrecompPoints.SRS[i]) {
+			t.Fatalf("SRS point %d is incorrect", i)
+		}
+		var fromAffine banderwagon.Element
+		fromAffine.Identity()
+		fromAffine.AddMixed(&fromAffine, srsAffine[i])
+		if !fromAffine.Equal(&srs[i]) {
+			t.Fatalf("affine SRS point %d is incorrect", i)
+		}
+	}
+
+	// The returned slice is a copy, so modifying it doesn't change the config.
+	srs[0].Identity()
+	if ipaConf.SRSPrecompPoints.SRS[0].IsIdentity() {
+		t.Fatal("modifying the returned SRS changed the config")
+	}
+}
+
+func TestNewIPASettingsWithSRS(t *testing.T) {
+	srs := GenerateRandomPoints(16)
+	ipaConf, err := NewIPASettingsWithSRS(srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var point fr.Element
+	point.SetUint64(123456789)
+
+	poly := make([]fr.Element, len(srs))
+	for i := range poly {
+		poly[i].SetUint64(uint64(i + 1))
+	}
+	comm := ipaConf.Commit(poly)
+	proof := CreateIPAProof(common.NewTranscript("ipa"), ipaConf, comm, poly, point)
+	if len(proof.L) != 4 {
+		t.Fatalf("expected 4 rounds, got %d", len(proof.L))
+	}
+
+	lagrange_coeffs := ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(point)
+	inner_product := InnerProd(poly, lagrange_coeffs)
+	if !CheckIPAProof(common.NewTranscript("ipa"), ipaConf, comm, proof, point, inner_product) {
+		t.Fatal("proof over a 16 point SRS is invalid")
+	}
+
+	var wrong_inner_product fr.Element
+	wrong_inner_product.Add(&inner_product, &poly[0])
+	if CheckIPAProof(common.NewTranscript("ipa"), ipaConf, comm, proof, point, wrong_inner_product) {
+		t.Fatal("proof should not verify with the wrong inner product")
+	}
+
+	var identity banderwagon.Element
+	identity.Identity()
+	invalidSRSs := map[string][]banderwagon.Element{
+		"empty":          nil,
+		"not power of 2": srs[:12],
+		"identity":       append([]banderwagon.Element{identity}, srs[1:]...),
+		"duplicate":      append([]banderwagon.Element{srs[1]}, srs[1:]...),
+		"generator":      append([]banderwagon.Element{banderwagon.Generator}, srs[1:]...),
+	}
+	for name, invalid := range invalidSRSs {
+		if _, err := NewIPASettingsWithSRS(invalid); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseSRSHex(t *testing.T) {
+	srs := GenerateRandomPoints(256)
+
+	hexLines := make([]string, len(srs))
+	for i, point := range srs {
+		bytes := point.Bytes()
+		hexLines[i] = hex.EncodeToString(bytes[:])
+	}
+	// The "0x" prefix is optional.
+	hexLines[1] = "0x" + hexLines[1]
+
+	got, err := ParseSRSHex(hexLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(srs) {
+		t.Fatalf("expected %d points, got %d", len(srs), len(got))
+	}
+	for i := range srs {
+		if !got[i].Equal(&srs[i]) {
+			t.Fatalf("point %d does not match the canonical SRS", i)
+		}
+	}
+
+	corruptLines := map[string]string{
+		"not on the subgroup": "280e608d5bbbe84b16aac62aa450e8921840ea563f1c9c266e0240d89cbe6a78",
+		"invalid hex":         "zz" + hexLines[7][2:],
+		"short point":         hexLines[7][:62],
+	}
+	for name, corrupt := range corruptLines {
+		lines := append([]string{}, hexLines...)
+		lines[7] = corrupt
+		if _, err := ParseSRSHex(lines); err == nil {
+			t.Fatalf("%s: expected an error for a corrupted line", name)
+		}
+	}
+}
+
 func test_serialize_deserialize_proof(proof IPAProof) {
 	buf := new(bytes.Buffer)
 	proof.Write(buf)
@@ -239,3 +746,143 @@ func removeDuplicatePoints(intSlice []banderwagon.Element) []banderwagon.Element
 	}
 	return list
 }
+
+// createTestIPAProofs creates n proofs of random polynomials at random points
+// outside the domain, along with what is needed to verify them.
+func createTestIPAProofs(ipaConf *IPAConfig, n int) ([]banderwagon.Element, []IPAProof, []fr.Element, []fr.Element) {
+	commitments := make([]banderwagon.Element, n)
+	proofs := make([]IPAProof, n)
+	points := make([]fr.Element, n)
+	innerProds := make([]fr.Element, n)
+	for j := 0; j < n; j++ {
+		poly := make([]fr.Element, common.POLY_DEGREE)
+		for i := range poly {
+			poly[i].SetRandom()
+		}
+		points[j].SetUint64(uint64(1000 + j))
+
+		commitments[j] = ipaConf.Commit(poly)
+		proofs[j] = CreateIPAProof(common.NewTranscript("ipa"), ipaConf, commitments[j], poly, points[j])
+		innerProds[j] = InnerProd(poly, ipaConf.PrecomputedWeights.ComputeBarycentricCoefficients(points[j]))
+	}
+	return commitments, proofs, points, innerProds
+}
+
+func newTestTranscripts(n int) []Transcript {
+	transcripts := make([]Transcript, n)
+	for i := range transcripts {
+		transcripts[i] = common.NewTranscript("ipa")
+	}
+	return transcripts
+}
+
+func TestCheckIPAProofBatch(t *testing.T) {
+	ipaConf := getTestIPAConfig()
+
+	const n = 4
+	commitments, proofs, points, innerProds := createTestIPAProofs(ipaConf, n)
+
+	ok, err := CheckIPAProofBatch(newTestTranscripts(n), ipaConf, commitments, proofs, points, innerProds, true)
+	if err != nil || !ok {
+		t.Fatalf("valid proofs should pass batch verification: %v", err)
+	}
+
+	ok, err = CheckIPAProofBatch(nil, ipaConf, nil, nil, nil, nil, true)
+	if err != nil || !ok {
+		t.Fatal("an empty batch should be valid")
+	}
+
+	// Tamper with one of the proofs.
+	one := fr.One()
+	badProofs := append([]IPAProof{}, proofs...)
+	badProofs[2].A_scalar.Add(&badProofs[2].A_scalar, &one)
+
+	ok, err = CheckIPAProofBatch(newTestTranscripts(n), ipaConf, commitments, badProofs, points, innerProds, false)
+	if err != nil || ok {
+		t.Fatal("batch with an invalid proof should fail without an error")
+	}
+	ok, err = CheckIPAProofBatch(newTestTranscripts(n), ipaConf, commitments, badProofs, points, innerProds, true)
+	if ok || err == nil || err.Error() != "invalid proofs at indices [2]" {
+		t.Fatalf("batch with an invalid proof should identify it, got %v", err)
+	}
+
+	// Swapping the inner products makes both proofs invalid.
+	badInnerProds := append([]fr.Element{}, innerProds...)
+	badInnerProds[0], badInnerProds[1] = badInnerProds[1], badInnerProds[0]
+	ok, err = CheckIPAProofBatch(newTestTranscripts(n), ipaConf, commitments, proofs, points, badInnerProds, true)
+	if ok || err == nil || err.Error() != "invalid proofs at indices [0 1]" {
+		t.Fatalf("batch with invalid proofs should identify them, got %v", err)
+	}
+
+	if _, err := CheckIPAProofBatch(newTestTranscripts(n-1), ipaConf, commitments, proofs, points, innerProds, true); err == nil {
+		t.Fatal("expected an error for a wrong number of transcripts")
+	}
+	badProofs = append([]IPAProof{}, proofs...)
+	badProofs[1].L = badProofs[1].L[1:]
+	if _, err := CheckIPAProofBatch(newTestTranscripts(n), ipaConf, commitments, badProofs, points, innerProds, true); err == nil {
+		t.Fatal("expected an error for a malformed proof")
+	}
+}
+
+func BenchmarkCheckIPAProof(b *testing.B) {
+	ipaConf := getTestIPAConfig()
+	commitments, proofs, points, innerProds := createTestIPAProofs(ipaConf, 1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !CheckIPAProof(common.NewTranscript("ipa"), ipaConf, commitments[0], proofs[0], points[0], innerProds[0]) {
+			b.Fatal("proof should be valid")
+		}
+	}
+}
+
+func BenchmarkComputeFoldingScalars(b *testing.B) {
+	challenges_inv := make([]fr.Element, ipaProofRounds)
+	for i := range challenges_inv {
+		challenges_inv[i].SetRandom()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = computeFoldingScalars(challenges_inv, DOMAIN_SIZE)
+	}
+}
+
+func BenchmarkCheckIPAProofBatch(b *testing.B) {
+	ipaConf := getTestIPAConfig()
+
+	const n = 100
+	commitments, proofs, points, innerProds := createTestIPAProofs(ipaConf, n)
+
+	b.Run("individually", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < n; j++ {
+				if !CheckIPAProof(common.NewTranscript("ipa"), ipaConf, commitments[j], proofs[j], points[j], innerProds[j]) {
+					b.Fatal("proof should be valid")
+				}
+			}
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ok, err := CheckIPAProofBatch(newTestTranscripts(n), ipaConf, commitments, proofs, points, innerProds, false)
+			if err != nil || !ok {
+				b.Fatal("proofs should be valid")
+			}
+		}
+	})
+}
+
+var (
+	testIPAConfOnce sync.Once
+	testIPAConf     *IPAConfig
+)
+
+// getTestIPAConfig returns an IPAConfig shared between tests, since
+// building the precomputed tables is expensive.
+func getTestIPAConfig() *IPAConfig {
+	testIPAConfOnce.Do(func() {
+		testIPAConf = NewIPASettings()
+	})
+	return testIPAConf
+}
@@ -1,21 +1,59 @@
 package ipa
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"math/big"
 
 	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
 	"github.com/crate-crypto/go-ipa/banderwagon"
 	"github.com/crate-crypto/go-ipa/common"
 )
 
+// The number of rounds of an IPA proof over the domain.
+// Serialised proofs do not carry their length, so deserialisation reads
+// exactly this many L and R points and never allocates based on the input.
+// It is log2(DOMAIN_SIZE), spelled out so that IPAProofSize can be a constant.
+const ipaProofRounds = 8
+
+// ErrInvalidProof is wrapped by every error returned when decoding a malformed IPA proof.
+var ErrInvalidProof = errors.New("invalid IPA proof")
+
+// invalidProofError wraps the reason a proof was rejected, so that errors.Is matches
+// both ErrInvalidProof and the underlying error, e.g. banderwagon.ErrInvalidEncoding.
+type invalidProofError struct {
+	err error
+}
+
+func (e invalidProofError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidProof, e.err)
+}
+
+func (e invalidProofError) Unwrap() error {
+	return e.err
+}
+
+func (e invalidProofError) Is(target error) bool {
+	return target == ErrInvalidProof
+}
+
 type IPAProof struct {
 	L        []banderwagon.Element
 	R        []banderwagon.Element
 	A_scalar fr.Element
 }
 
-func CreateIPAProof(transcript *common.Transcript, ic *IPAConfig, commitment banderwagon.Element, a []fr.Element, eval_point fr.Element) IPAProof {
+// CreateIPAProof proves that the vector a, committed to in commitment, evaluates to
+// <a, b> at eval_point, where b are the barycentric coefficients of eval_point.
+//
+// The proof is not hiding, so it is not zero-knowledge: no blinding factors are used, and
+// the L and R points and the final scalar reveal information about a. Callers that need
+// to hide a must not use this proof as is. Since there are no secret scalars, there is
+// nothing to zeroize either, see fr.Element.Zeroize.
+func CreateIPAProof(transcript Transcript, ic *IPAConfig, commitment banderwagon.Element, a []fr.Element, eval_point fr.Element) IPAProof {
 	transcript.DomainSep("ipa")
 
 	b := ic.PrecomputedWeights.ComputeBarycentricCoefficients(eval_point)
@@ -31,54 +69,73 @@ func CreateIPAProof(transcript *common.Transcript, ic *IPAConfig, commitment ban
 
 	num_rounds := ic.num_ipa_rounds
 
-	current_basis := ic.SRSPrecompPoints.SRS
+	state := ipaProverState{
+		a:     a,
+		b:     b,
+		basis: ic.SRSPrecompPoints.SRS,
+	}
 
 	L := make([]banderwagon.Element, num_rounds)
 	R := make([]banderwagon.Element, num_rounds)
 
 	for i := 0; i < int(num_rounds); i++ {
+		L[i], R[i] = state.foldRound(transcript, &q)
+	}
 
-		a_L, a_R := splitScalars(a)
+	return state.finalize(L, R)
+}
 
-		b_L, b_R := splitScalars(b)
+// ipaProverState holds the vectors that the prover halves on each round of the IPA.
+type ipaProverState struct {
+	a     []fr.Element
+	b     []fr.Element
+	basis []banderwagon.Element
+}
 
-		G_L, G_R := splitPoints(current_basis)
+// foldRound computes the L and R commitments of a single round, appends them
+// to the transcript and folds `a`, `b` and the basis using the round challenge.
+func (s *ipaProverState) foldRound(transcript Transcript, q *banderwagon.Element) (banderwagon.Element, banderwagon.Element) {
+	a_L, a_R := splitScalars(s.a)
 
-		z_L := InnerProd(a_R, b_L)
-		z_R := InnerProd(a_L, b_R)
+	b_L, b_R := splitScalars(s.b)
 
-		C_L_1 := commit(G_L, a_R)
-		C_L := commit([]banderwagon.Element{C_L_1, q}, []fr.Element{fr.One(), z_L})
+	G_L, G_R := splitPoints(s.basis)
 
-		C_R_1 := commit(G_R, a_L)
-		C_R := commit([]banderwagon.Element{C_R_1, q}, []fr.Element{fr.One(), z_R})
+	z_L := InnerProd(a_R, b_L)
+	z_R := InnerProd(a_L, b_R)
 
-		L[i] = C_L
-		R[i] = C_R
+	C_L_1 := commit(G_L, a_R)
+	C_L := commit([]banderwagon.Element{C_L_1, *q}, []fr.Element{fr.One(), z_L})
 
-		transcript.AppendPoint(&C_L, "L")
-		transcript.AppendPoint(&C_R, "R")
-		x := transcript.ChallengeScalar("x")
+	C_R_1 := commit(G_R, a_L)
+	C_R := commit([]banderwagon.Element{C_R_1, *q}, []fr.Element{fr.One(), z_R})
 
-		var xInv fr.Element
-		xInv.Inverse(&x)
+	transcript.AppendPoint(&C_L, "L")
+	transcript.AppendPoint(&C_R, "R")
+	x := transcript.ChallengeScalar("x")
 
-		// TODO: We could use a for loop here like in the Rust code
-		a = foldScalars(a_L, a_R, x)
-		b = foldScalars(b_L, b_R, xInv)
+	var xInv fr.Element
+	xInv.Inverse(&x)
 
-		current_basis = foldPoints(G_L, G_R, xInv)
+	s.a = FoldScalars(s.a, x)
+	s.b = FoldScalars(s.b, xInv)
 
-	}
+	s.basis = FoldPoints(s.basis, xInv)
+
+	return C_L, C_R
+}
 
-	if len(a) != 1 {
+// finalize builds the proof once all of the rounds have been completed,
+// at which point `a` must have been reduced to a single scalar.
+func (s *ipaProverState) finalize(L []banderwagon.Element, R []banderwagon.Element) IPAProof {
+	if len(s.a) != 1 {
 		panic("length of `a` should be 1 at the end of the reduction")
 	}
 
 	return IPAProof{
 		L:        L,
 		R:        R,
-		A_scalar: a[0],
+		A_scalar: s.a[0],
 	}
 }
 
@@ -92,26 +149,130 @@ func (ip *IPAProof) Write(w io.Writer) {
 	binary.Write(w, binary.BigEndian, ip.A_scalar.BytesLE())
 }
 
+// Read reads a proof written by Write. Like UnmarshalBinary, it rejects a final scalar
+// that isn't smaller than the modulus. It panics if the input is truncated or invalid,
+// so DeserializeIPAProof should be used for untrusted input.
 func (ip *IPAProof) Read(r io.Reader) {
-	var L []banderwagon.Element
-	for i := 0; i < 8; i++ {
+	L := make([]banderwagon.Element, 0, ipaProofRounds)
+	for i := 0; i < ipaProofRounds; i++ {
 		L_i := common.ReadPoint(r)
 		L = append(L, *L_i)
 	}
 	ip.L = L
-	var R []banderwagon.Element
-	for i := 0; i < 8; i++ {
+	R := make([]banderwagon.Element, 0, ipaProofRounds)
+	for i := 0; i < ipaProofRounds; i++ {
 		R_i := common.ReadPoint(r)
 		R = append(R, *R_i)
 	}
 	ip.R = R
 
-	A_Scalar := common.ReadScalar(r)
-	ip.A_scalar = *A_Scalar
+	var A_scalar_bytes [32]byte
+	if _, err := io.ReadFull(r, A_scalar_bytes[:]); err != nil {
+		panic("error reading bytes")
+	}
+	A_scalar, err := scalarFromCanonicalBytesLE(A_scalar_bytes[:])
+	if err != nil {
+		panic(fmt.Sprintf("invalid final scalar: %s", err))
+	}
+	ip.A_scalar = A_scalar
+}
+
+// IPAProofSize is the size of a serialised IPA proof: the L points,
+// then the R points, then the final scalar in little endian.
+const IPAProofSize = 2*ipaProofRounds*32 + 32
+
+// MarshalBinary implements encoding.BinaryMarshaler, using the same format as Write.
+func (ip IPAProof) MarshalBinary() ([]byte, error) {
+	if len(ip.L) != len(ip.R) {
+		return nil, fmt.Errorf("L has %d points while R has %d", len(ip.L), len(ip.R))
+	}
+	if len(ip.L) != ipaProofRounds {
+		return nil, fmt.Errorf("proof has %d rounds, expected %d", len(ip.L), ipaProofRounds)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(IPAProofSize)
+	ip.Write(&buf)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// The input must be exactly IPAProofSize bytes long, every point must be a valid
+// encoding of a group element and the scalar must be smaller than the modulus.
+// Every error it returns wraps ErrInvalidProof.
+func (ip *IPAProof) UnmarshalBinary(data []byte) error {
+	if err := ip.unmarshalBinary(data); err != nil {
+		return invalidProofError{err}
+	}
+	return nil
+}
+
+func (ip *IPAProof) unmarshalBinary(data []byte) error {
+	if len(data) != IPAProofSize {
+		return fmt.Errorf("got %d bytes, expected %d", len(data), IPAProofSize)
+	}
+
+	L := make([]banderwagon.Element, ipaProofRounds)
+	for i := range L {
+		if err := L[i].SetBytes(data[:32]); err != nil {
+			return fmt.Errorf("invalid L[%d]: %w", i, err)
+		}
+		data = data[32:]
+	}
+	R := make([]banderwagon.Element, ipaProofRounds)
+	for i := range R {
+		if err := R[i].SetBytes(data[:32]); err != nil {
+			return fmt.Errorf("invalid R[%d]: %w", i, err)
+		}
+		data = data[32:]
+	}
+	A_scalar, err := scalarFromCanonicalBytesLE(data)
+	if err != nil {
+		return fmt.Errorf("invalid final scalar: %w", err)
+	}
+
+	ip.L = L
+	ip.R = R
+	ip.A_scalar = A_scalar
+	return nil
+}
+
+// DeserializeIPAProof reads a proof written by Write, returning an error instead of
+// panicking on invalid input. It reads exactly IPAProofSize bytes, since the number of
+// rounds is fixed by the domain size, so the input can't make it allocate more.
+// Every error it returns wraps ErrInvalidProof, as well as the underlying cause: a
+// truncated input wraps io.ErrUnexpectedEOF or io.EOF, and an invalid point the
+// banderwagon error, e.g. banderwagon.ErrInvalidEncoding.
+func DeserializeIPAProof(r io.Reader) (IPAProof, error) {
+	buf := make([]byte, IPAProofSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return IPAProof{}, invalidProofError{fmt.Errorf("reading proof: %w", err)}
+	}
+	var ip IPAProof
+	if err := ip.UnmarshalBinary(buf); err != nil {
+		return IPAProof{}, err
+	}
+	return ip, nil
+}
+
+// scalarFromCanonicalBytesLE deserialises a little endian scalar, rejecting
+// values that aren't reduced modulo the scalar field.
+func scalarFromCanonicalBytesLE(b []byte) (fr.Element, error) {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	if new(big.Int).SetBytes(be).Cmp(fr.Modulus()) >= 0 {
+		return fr.Element{}, fmt.Errorf("scalar is not smaller than the modulus")
+	}
+
+	var scalar fr.Element
+	scalar.SetBytes(be)
+	return scalar, nil
 }
 
 func (ip IPAProof) Equal(other IPAProof) bool {
-	num_rounds := 8
+	num_rounds := ipaProofRounds
 	if len(ip.L) != len(other.L) {
 		return false
 	}
@@ -0,0 +1,22 @@
+package ipa
+
+import (
+	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
+	"github.com/crate-crypto/go-ipa/banderwagon"
+)
+
+// Transcript is the Fiat-Shamir transcript used to derive the challenges of a proof.
+//
+// common.Transcript is the default implementation, and common.NewTranscriptWithHash
+// allows to use a hash other than sha256. Prover and verifier must use the same one.
+//
+// The label a common.Transcript is created with is absorbed before any message, so it
+// works as a domain separator: a proof created under one label doesn't verify under
+// another. Verkle trie proofs use "multiproof" for CreateMultiProof and CheckMultiProof,
+// and other protocols building on this package should pick their own label.
+type Transcript interface {
+	DomainSep(label string)
+	AppendScalar(scalar *fr.Element, label string)
+	AppendPoint(point *banderwagon.Element, label string)
+	ChallengeScalar(label string) fr.Element
+}
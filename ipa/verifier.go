@@ -1,12 +1,13 @@
 package ipa
 
 import (
+	"fmt"
+
 	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
 	"github.com/crate-crypto/go-ipa/banderwagon"
-	"github.com/crate-crypto/go-ipa/common"
 )
 
-func CheckIPAProof(transcript *common.Transcript, ic *IPAConfig, commitment banderwagon.Element, proof IPAProof, eval_point fr.Element, inner_prod fr.Element) bool {
+func CheckIPAProof(transcript Transcript, ic *IPAConfig, commitment banderwagon.Element, proof IPAProof, eval_point fr.Element, inner_prod fr.Element) bool {
 	transcript.DomainSep("ipa")
 
 	if len(proof.L) != len(proof.R) {
@@ -16,14 +17,42 @@ func CheckIPAProof(transcript *common.Transcript, ic *IPAConfig, commitment band
 		panic("The number of points for L or R should be equal to the number of rounds")
 	}
 
-	b := ic.PrecomputedWeights.ComputeBarycentricCoefficients(eval_point)
-
 	transcript.AppendPoint(&commitment, "C")
 	transcript.AppendScalar(&eval_point, "input point")
 	transcript.AppendScalar(&inner_prod, "output point")
 
 	w := transcript.ChallengeScalar("w")
 
+	challenges := generateChallenges(transcript, &proof)
+
+	return checkIPAProofWithChallenges(ic, commitment, proof, eval_point, inner_prod, w, challenges)
+}
+
+// CheckIPAProofWithChallenges checks an IPA proof using the externally supplied challenges
+// `w` and `challenges` (one per round) instead of deriving them from a transcript.
+//
+// This is a debugging and testing tool: it allows to check whether the proof relation holds
+// assuming the challenges are correct, which isolates transcript bugs from arithmetic bugs
+// when comparing against other implementations.
+// It is NOT sound to use it for verifying proofs in production, since the prover could
+// choose the challenges.
+func CheckIPAProofWithChallenges(ic *IPAConfig, commitment banderwagon.Element, proof IPAProof, eval_point fr.Element, inner_prod fr.Element, w fr.Element, challenges []fr.Element) bool {
+	if len(proof.L) != len(proof.R) {
+		panic("L and R should be the same size")
+	}
+	if len(proof.L) != int(ic.num_ipa_rounds) {
+		panic("The number of points for L or R should be equal to the number of rounds")
+	}
+	if len(challenges) != len(proof.L) {
+		panic("The number of challenges should be equal to the number of rounds")
+	}
+
+	return checkIPAProofWithChallenges(ic, commitment, proof, eval_point, inner_prod, w, challenges)
+}
+
+func checkIPAProofWithChallenges(ic *IPAConfig, commitment banderwagon.Element, proof IPAProof, eval_point fr.Element, inner_prod fr.Element, w fr.Element, challenges []fr.Element) bool {
+	b := ic.PrecomputedWeights.ComputeBarycentricCoefficients(eval_point)
+
 	var q banderwagon.Element
 	q.ScalarMul(&ic.SRSPrecompPoints.Q, &w)
 
@@ -31,7 +60,6 @@ func CheckIPAProof(transcript *common.Transcript, ic *IPAConfig, commitment band
 	qy.ScalarMul(&q, &inner_prod)
 	commitment.Add(&commitment, &qy)
 
-	challenges := generateChallenges(transcript, &proof)
 	challenges_inv := fr.BatchInvert(challenges)
 
 	// Compute expected commitment
@@ -43,21 +71,9 @@ func CheckIPAProof(transcript *common.Transcript, ic *IPAConfig, commitment band
 		commitment = commit([]banderwagon.Element{commitment, L, R}, []fr.Element{fr.One(), x, challenges_inv[i]})
 	}
 
-	g := ic.SRSPrecompPoints.SRS
-
 	// We compute the folding-scalars for g and b.
-	foldingScalars := make([]fr.Element, len(g))
-	for i := 0; i < len(g); i++ {
-		scalar := fr.One()
-
-		for challengeIdx := 0; challengeIdx < len(challenges); challengeIdx++ {
-			if i&(1<<(7-challengeIdx)) > 0 {
-				scalar.Mul(&scalar, &challenges_inv[challengeIdx])
-			}
-		}
-		foldingScalars[i] = scalar
-	}
-	g0 := multiScalar(g, foldingScalars)
+	foldingScalars := computeFoldingScalars(challenges_inv, len(ic.SRSPrecompPoints.SRS))
+	g0 := foldGenerators(ic, foldingScalars)
 	b0 := InnerProd(b, foldingScalars)
 
 	var got banderwagon.Element
@@ -76,7 +92,120 @@ func CheckIPAProof(transcript *common.Transcript, ic *IPAConfig, commitment band
 	return got.Equal(&commitment)
 }
 
-func generateChallenges(transcript *common.Transcript, proof *IPAProof) []fr.Element {
+// CheckIPAProofBatch checks many IPA proofs at once. Each proof is checked against its own
+// transcript, commitment, evaluation point and inner product, at the same index.
+//
+// Every verification equation is multiplied by a random weight and they are all added up,
+// so the whole batch costs a single MSM instead of one per proof. If any proof is invalid,
+// the sum is not the identity except with negligible probability.
+//
+// If the batch fails and identifyInvalid is true, the proofs are checked one by one and the
+// returned error lists the indices of the invalid ones.
+func CheckIPAProofBatch(transcripts []Transcript, ic *IPAConfig, commitments []banderwagon.Element, proofs []IPAProof, eval_points []fr.Element, inner_prods []fr.Element, identifyInvalid bool) (bool, error) {
+	n := len(proofs)
+	if len(transcripts) != n || len(commitments) != n || len(eval_points) != n || len(inner_prods) != n {
+		return false, fmt.Errorf("got %d transcripts, %d commitments, %d evaluation points and %d inner products for %d proofs",
+			len(transcripts), len(commitments), len(eval_points), len(inner_prods), n)
+	}
+	if n == 0 {
+		return true, nil
+	}
+
+	num_rounds := int(ic.num_ipa_rounds)
+	ws := make([]fr.Element, n)
+	challenges := make([][]fr.Element, n)
+	allChallenges := make([]fr.Element, 0, n*num_rounds)
+	for j := range proofs {
+		if len(proofs[j].L) != len(proofs[j].R) {
+			return false, fmt.Errorf("proof %d: L and R have different sizes", j)
+		}
+		if len(proofs[j].L) != num_rounds {
+			return false, fmt.Errorf("proof %d: got %d rounds, expected %d", j, len(proofs[j].L), num_rounds)
+		}
+
+		transcript := transcripts[j]
+		transcript.DomainSep("ipa")
+		transcript.AppendPoint(&commitments[j], "C")
+		transcript.AppendScalar(&eval_points[j], "input point")
+		transcript.AppendScalar(&inner_prods[j], "output point")
+		ws[j] = transcript.ChallengeScalar("w")
+
+		challenges[j] = generateChallenges(transcript, &proofs[j])
+		allChallenges = append(allChallenges, challenges[j]...)
+	}
+	allChallengesInv := fr.BatchInvert(allChallenges)
+
+	// For each proof, the equation checked by CheckIPAProof can be rearranged as
+	// C + SUM(x_i * L_i + x_i^-1 * R_i) + w * (y - a * b0) * Q - a * g0 = 0
+	// where g0 is the SRS folded with the folding scalars. The terms on Q and on the SRS
+	// are accumulated across proofs, so each of those points appears once in the MSM.
+	g := ic.SRSPrecompPoints.SRS
+	gScalars := make([]fr.Element, len(g))
+	var qScalar fr.Element
+
+	points := make([]banderwagon.Element, 0, n*(1+2*num_rounds)+1)
+	scalars := make([]fr.Element, 0, n*(1+2*num_rounds)+1)
+	for j := range proofs {
+		var r fr.Element
+		if _, err := r.SetRandom(); err != nil {
+			return false, fmt.Errorf("generating random weight: %s", err)
+		}
+
+		challenges_inv := allChallengesInv[j*num_rounds : (j+1)*num_rounds]
+		foldingScalars := computeFoldingScalars(challenges_inv, len(g))
+		b := ic.PrecomputedWeights.ComputeBarycentricCoefficients(eval_points[j])
+		b0 := InnerProd(b, foldingScalars)
+
+		points = append(points, commitments[j])
+		scalars = append(scalars, r)
+		for i := 0; i < num_rounds; i++ {
+			var x, xInv fr.Element
+			x.Mul(&r, &challenges[j][i])
+			xInv.Mul(&r, &challenges_inv[i])
+			points = append(points, proofs[j].L[i], proofs[j].R[i])
+			scalars = append(scalars, x, xInv)
+		}
+
+		var tmp fr.Element
+		tmp.Mul(&proofs[j].A_scalar, &b0)
+		tmp.Sub(&inner_prods[j], &tmp)
+		tmp.Mul(&tmp, &ws[j])
+		tmp.Mul(&tmp, &r)
+		qScalar.Add(&qScalar, &tmp)
+
+		var ra fr.Element
+		ra.Mul(&r, &proofs[j].A_scalar)
+		ra.Neg(&ra)
+		for k := range gScalars {
+			tmp.Mul(&ra, &foldingScalars[k])
+			gScalars[k].Add(&gScalars[k], &tmp)
+		}
+	}
+	points = append(points, ic.SRSPrecompPoints.Q)
+	scalars = append(scalars, qScalar)
+
+	// The SRS part uses the precomputed tables, which is faster than a generic MSM.
+	result := multiScalar(points, scalars)
+	srsPart := ic.Commit(gScalars)
+	result.Add(&result, &srsPart)
+
+	if result.IsIdentity() {
+		return true, nil
+	}
+	if !identifyInvalid {
+		return false, nil
+	}
+
+	var invalid []int
+	for j := range proofs {
+		if !checkIPAProofWithChallenges(ic, commitments[j], proofs[j], eval_points[j], inner_prods[j], ws[j], challenges[j]) {
+			invalid = append(invalid, j)
+		}
+	}
+	return false, fmt.Errorf("invalid proofs at indices %v", invalid)
+}
+
+func generateChallenges(transcript Transcript, proof *IPAProof) []fr.Element {
 
 	challenges := make([]fr.Element, len(proof.L))
 	for i := 0; i < len(proof.L); i++ {
@@ -86,3 +215,51 @@ func generateChallenges(transcript *common.Transcript, proof *IPAProof) []fr.Ele
 	}
 	return challenges
 }
+
+// FoldGenerators computes the generator that the SRS is reduced to once it has
+// been folded with the round challenges of an IPA proof.
+// This is the point the verifier compares the final scalar of the proof against,
+// which can be cached when verifying many proofs that share the same challenges.
+func FoldGenerators(ic *IPAConfig, challenges []fr.Element) banderwagon.Element {
+	if len(challenges) != int(ic.num_ipa_rounds) {
+		panic("The number of challenges should be equal to the number of rounds")
+	}
+
+	challenges_inv := fr.BatchInvert(challenges)
+	foldingScalars := computeFoldingScalars(challenges_inv, len(ic.SRSPrecompPoints.SRS))
+
+	return foldGenerators(ic, foldingScalars)
+}
+
+// foldGenerators folds the SRS with the scalars from computeFoldingScalars. It is shared by
+// FoldGenerators and the verifier, which reuses the folding scalars to fold b as well.
+func foldGenerators(ic *IPAConfig, foldingScalars []fr.Element) banderwagon.Element {
+	return multiScalar(ic.SRSPrecompPoints.SRS, foldingScalars)
+}
+
+// Computes the scalars s_i such that folding a vector v with the challenges
+// results in SUM v_i * s_i
+// s_i is the product of the inverse challenges for the rounds where the
+// i-th element ended up in the right half of the vector.
+// The inverses should come from a single fr.BatchInvert of all the challenges.
+//
+// The first round decides the most significant bit of i, so the vector is built by
+// starting from the last round and doubling it on each one: the new upper half is the
+// lower half times the inverse challenge of that round. This costs n multiplications
+// instead of up to n * num_rounds.
+// panics if n is not 2^num_rounds
+func computeFoldingScalars(challenges_inv []fr.Element, n int) []fr.Element {
+	num_rounds := len(challenges_inv)
+	if n != 1<<num_rounds {
+		panic(fmt.Sprintf("cannot fold %d elements in %d rounds", n, num_rounds))
+	}
+
+	foldingScalars := make([]fr.Element, n)
+	foldingScalars[0] = fr.One()
+	for round, size := num_rounds-1, 1; round >= 0; round, size = round-1, size*2 {
+		for i := 0; i < size; i++ {
+			foldingScalars[size+i].Mul(&foldingScalars[i], &challenges_inv[round])
+		}
+	}
+	return foldingScalars
+}
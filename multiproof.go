@@ -16,7 +16,7 @@ type MultiProof struct {
 	D   banderwagon.Element
 }
 
-func CreateMultiProof(transcript *common.Transcript, ipaConf *ipa.IPAConfig, Cs []*banderwagon.Element, fs [][]fr.Element, zs []uint8) *MultiProof {
+func CreateMultiProof(transcript ipa.Transcript, ipaConf *ipa.IPAConfig, Cs []*banderwagon.Element, fs [][]fr.Element, zs []uint8) *MultiProof {
 	transcript.DomainSep("multiproof")
 
 	if len(Cs) != len(fs) {
@@ -112,7 +112,47 @@ func CreateMultiProof(transcript *common.Transcript, ipaConf *ipa.IPAConfig, Cs
 	}
 }
 
-func CheckMultiProof(transcript *common.Transcript, ipaConf *ipa.IPAConfig, proof *MultiProof, Cs []*banderwagon.Element, ys []*fr.Element, zs []uint8) bool {
+func CheckMultiProof(transcript ipa.Transcript, ipaConf *ipa.IPAConfig, proof *MultiProof, Cs []*banderwagon.Element, ys []*fr.Element, zs []uint8) bool {
+	E_minus_D, t, g_2_t := reduceMultiProof(transcript, proof, Cs, ys, zs)
+
+	return ipa.CheckIPAProof(transcript, ipaConf, E_minus_D, proof.IPA, t, g_2_t)
+}
+
+// CheckMultiProofBatch checks many multiproofs at once. The i-th proof is checked
+// against transcripts[i], Cs[i], ys[i] and zs[i].
+//
+// Each multiproof reduces to a single IPA proof, and those are checked with
+// ipa.CheckIPAProofBatch, so the whole batch costs a single MSM.
+// It returns false if any of the proofs is invalid or malformed, or if the lengths of
+// the inputs don't match.
+func CheckMultiProofBatch(transcripts []ipa.Transcript, ipaConf *ipa.IPAConfig, proofs []*MultiProof, Cs [][]*banderwagon.Element, ys [][]*fr.Element, zs [][]uint8) bool {
+	n := len(proofs)
+	if len(transcripts) != n || len(Cs) != n || len(ys) != n || len(zs) != n {
+		return false
+	}
+	// Check the openings up front, since reduceMultiProof panics on malformed ones.
+	for i := range proofs {
+		if proofs[i] == nil || len(Cs[i]) == 0 || len(Cs[i]) != len(ys[i]) || len(Cs[i]) != len(zs[i]) {
+			return false
+		}
+	}
+
+	commitments := make([]banderwagon.Element, n)
+	ipaProofs := make([]ipa.IPAProof, n)
+	eval_points := make([]fr.Element, n)
+	inner_prods := make([]fr.Element, n)
+	for i := range proofs {
+		commitments[i], eval_points[i], inner_prods[i] = reduceMultiProof(transcripts[i], proofs[i], Cs[i], ys[i], zs[i])
+		ipaProofs[i] = proofs[i].IPA
+	}
+
+	ok, err := ipa.CheckIPAProofBatch(transcripts, ipaConf, commitments, ipaProofs, eval_points, inner_prods, false)
+	return err == nil && ok
+}
+
+// reduceMultiProof runs the multiproof part of the verifier on the transcript, and returns
+// the commitment, evaluation point and evaluation that the IPA proof must be checked against.
+func reduceMultiProof(transcript ipa.Transcript, proof *MultiProof, Cs []*banderwagon.Element, ys []*fr.Element, zs []uint8) (banderwagon.Element, fr.Element, fr.Element) {
 	transcript.DomainSep("multiproof")
 
 	if len(Cs) != len(ys) {
@@ -177,7 +217,7 @@ func CheckMultiProof(transcript *common.Transcript, ipaConf *ipa.IPAConfig, proo
 	var E_minus_D banderwagon.Element
 	E_minus_D.Sub(&E, &proof.D)
 
-	return ipa.CheckIPAProof(transcript, ipaConf, E_minus_D, proof.IPA, t, g_2_t)
+	return E_minus_D, t, g_2_t
 }
 
 func domainToFr(in uint8) fr.Element {
@@ -196,6 +236,39 @@ func (mp *MultiProof) Read(r io.Reader) {
 	mp.D = *D
 	mp.IPA.Read(r)
 }
+
+// MarshalBinary implements encoding.BinaryMarshaler, using the same format as Write:
+// D followed by the IPA proof.
+func (mp MultiProof) MarshalBinary() ([]byte, error) {
+	ipaBytes, err := mp.IPA.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("serializing IPA proof: %s", err)
+	}
+	D := mp.D.Bytes()
+	return append(D[:], ipaBytes...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// The input must be exactly 32 + ipa.IPAProofSize bytes long.
+func (mp *MultiProof) UnmarshalBinary(data []byte) error {
+	if len(data) != 32+ipa.IPAProofSize {
+		return fmt.Errorf("got %d bytes, expected %d", len(data), 32+ipa.IPAProofSize)
+	}
+
+	var D banderwagon.Element
+	if err := D.SetBytes(data[:32]); err != nil {
+		return fmt.Errorf("invalid D: %w", err)
+	}
+	var proof ipa.IPAProof
+	if err := proof.UnmarshalBinary(data[32:]); err != nil {
+		return fmt.Errorf("deserializing IPA proof: %w", err)
+	}
+
+	mp.D = D
+	mp.IPA = proof
+	return nil
+}
+
 func (mp MultiProof) Equal(other MultiProof) bool {
 	if !mp.IPA.Equal(other.IPA) {
 		return false
@@ -3,6 +3,9 @@ package multiproof
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/crate-crypto/go-ipa/bandersnatch/fr"
@@ -95,7 +98,7 @@ func TestMultiProofConsistency(t *testing.T) {
 	}
 
 	// Check serialised bytes are consistent with other implementations
-	expected := "4f53588244efaf07a370ee3f9c467f933eed360d4fbf7a19dfc8bc49b67df4711bf1d0a720717cd6a8c75f1a668cb7cbdd63b48c676b89a7aee4298e71bd7f4013d7657146aa9736817da47051ed6a45fc7b5a61d00eb23e5df82a7f285cc10e67d444e91618465ca68d8ae4f2c916d1942201b7e2aae491ef0f809867d00e83468fb7f9af9b42ede76c1e90d89dd789ff22eb09e8b1d062d8a58b6f88b3cbe80136fc68331178cd45a1df9496ded092d976911b5244b85bc3de41e844ec194256b39aeee4ea55538a36139211e9910ad6b7a74e75d45b869d0a67aa4bf600930a5f760dfb8e4df9938d1f47b743d71c78ba8585e3b80aba26d24b1f50b36fa1458e79d54c05f58049245392bc3e2b5c5f9a1b99d43ed112ca82b201fb143d401741713188e47f1d6682b0bf496a5d4182836121efff0fd3b030fc6bfb5e21d6314a200963fe75cb856d444a813426b2084dfdc49dca2e649cb9da8bcb47859a4c629e97898e3547c591e39764110a224150d579c33fb74fa5eb96427036899c04154feab5344873d36a53a5baefd78c132be419f3f3a8dd8f60f72eb78dd5f43c53226f5ceb68947da3e19a750d760fb31fa8d4c7f53bfef11c4b89158aa56b1f4395430e16a3128f88e234ce1df7ef865f2d2c4975e8c82225f578310c31fd41d265fd530cbfa2b8895b228a510b806c31dff3b1fa5c08bffad443d567ed0e628febdd22775776e0cc9cebcaea9c6df9279a5d91dd0ee5e7a0434e989a160005321c97026cb559f71db23360105460d959bcdf74bee22c4ad8805a1d497507"
+	expected := consistencyProofHex
 
 	var buf = new(bytes.Buffer)
 	proof.Write(buf)
@@ -106,6 +109,181 @@ func TestMultiProofConsistency(t *testing.T) {
 	}
 }
 
+func TestCheckMultiProofBatch(t *testing.T) {
+	ipaConf := getTestIPAConfig()
+
+	const numProofs = 3
+	proofs, Cs, ys, zs := createTestMultiProofs(ipaConf, numProofs, 4)
+
+	if !CheckMultiProofBatch(newTestTranscripts(numProofs), ipaConf, proofs, Cs, ys, zs) {
+		t.Fatal("valid multiproofs should pass batch verification")
+	}
+	if !CheckMultiProofBatch(nil, ipaConf, nil, nil, nil, nil) {
+		t.Fatal("an empty batch should be valid")
+	}
+
+	// A wrong evaluation in one of the proofs.
+	var wrongY fr.Element
+	one := fr.One()
+	wrongY.Add(ys[1][2], &one)
+	badYs := append([][]*fr.Element{}, ys...)
+	badYs[1] = append([]*fr.Element{}, ys[1]...)
+	badYs[1][2] = &wrongY
+	if CheckMultiProofBatch(newTestTranscripts(numProofs), ipaConf, proofs, Cs, badYs, zs) {
+		t.Fatal("batch with a wrong evaluation should fail")
+	}
+
+	// A proof checked against the openings of another one.
+	badProofs := []*MultiProof{proofs[1], proofs[0], proofs[2]}
+	if CheckMultiProofBatch(newTestTranscripts(numProofs), ipaConf, badProofs, Cs, ys, zs) {
+		t.Fatal("batch with swapped proofs should fail")
+	}
+
+	// A malformed proof.
+	malformed := *proofs[0]
+	malformed.IPA.L = malformed.IPA.L[1:]
+	badProofs = []*MultiProof{&malformed, proofs[1], proofs[2]}
+	if CheckMultiProofBatch(newTestTranscripts(numProofs), ipaConf, badProofs, Cs, ys, zs) {
+		t.Fatal("batch with a malformed proof should fail")
+	}
+
+	// Mismatched lengths are rejected rather than panicking.
+	if CheckMultiProofBatch(newTestTranscripts(numProofs-1), ipaConf, proofs, Cs, ys, zs) {
+		t.Fatal("batch with too few transcripts should fail")
+	}
+	badYs = append([][]*fr.Element{}, ys...)
+	badYs[2] = ys[2][1:]
+	if CheckMultiProofBatch(newTestTranscripts(numProofs), ipaConf, proofs, Cs, badYs, zs) {
+		t.Fatal("batch with mismatched per-proof lengths should fail")
+	}
+	emptyCs := append([][]*banderwagon.Element{nil}, Cs[1:]...)
+	emptyYs := append([][]*fr.Element{nil}, ys[1:]...)
+	emptyZs := append([][]uint8{nil}, zs[1:]...)
+	if CheckMultiProofBatch(newTestTranscripts(numProofs), ipaConf, proofs, emptyCs, emptyYs, emptyZs) {
+		t.Fatal("batch with an empty opening set should fail")
+	}
+}
+
+func BenchmarkCheckMultiProofBatch(b *testing.B) {
+	ipaConf := getTestIPAConfig()
+
+	// Roughly the number of openings in a block, split in a few multiproofs.
+	const numQueries = 64
+	for _, numProofs := range []int{1, 8, 32} {
+		proofs, Cs, ys, zs := createTestMultiProofs(ipaConf, numProofs, numQueries)
+
+		b.Run(fmt.Sprintf("individually/proofs=%d", numProofs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range proofs {
+					if !CheckMultiProof(common.NewTranscript("multiproof"), ipaConf, proofs[j], Cs[j], ys[j], zs[j]) {
+						b.Fatal("proof should be valid")
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("batched/proofs=%d", numProofs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !CheckMultiProofBatch(newTestTranscripts(numProofs), ipaConf, proofs, Cs, ys, zs) {
+					b.Fatal("proofs should be valid")
+				}
+			}
+		})
+	}
+}
+
+// createTestMultiProofs creates numProofs multiproofs, each opening numQueries
+// random polynomials at points of the domain.
+func createTestMultiProofs(ipaConf *ipa.IPAConfig, numProofs, numQueries int) ([]*MultiProof, [][]*banderwagon.Element, [][]*fr.Element, [][]uint8) {
+	proofs := make([]*MultiProof, numProofs)
+	Cs := make([][]*banderwagon.Element, numProofs)
+	ys := make([][]*fr.Element, numProofs)
+	zs := make([][]uint8, numProofs)
+	for i := 0; i < numProofs; i++ {
+		fs := make([][]fr.Element, numQueries)
+		for j := 0; j < numQueries; j++ {
+			poly := make([]fr.Element, common.POLY_DEGREE)
+			for k := range poly {
+				poly[k].SetRandom()
+			}
+			z := uint8(i*numQueries + j*7)
+			C := ipaConf.Commit(poly)
+
+			fs[j] = poly
+			Cs[i] = append(Cs[i], &C)
+			zs[i] = append(zs[i], z)
+			ys[i] = append(ys[i], &poly[z])
+		}
+		proofs[i] = CreateMultiProof(common.NewTranscript("multiproof"), ipaConf, Cs[i], fs, zs[i])
+	}
+	return proofs, Cs, ys, zs
+}
+
+func newTestTranscripts(n int) []ipa.Transcript {
+	transcripts := make([]ipa.Transcript, n)
+	for i := range transcripts {
+		transcripts[i] = common.NewTranscript("multiproof")
+	}
+	return transcripts
+}
+
+var (
+	testIPAConfOnce sync.Once
+	testIPAConf     *ipa.IPAConfig
+)
+
+// getTestIPAConfig returns an IPAConfig shared between tests, since
+// building the precomputed tables is expensive.
+func getTestIPAConfig() *ipa.IPAConfig {
+	testIPAConfOnce.Do(func() {
+		testIPAConf = ipa.NewIPASettings()
+	})
+	return testIPAConf
+}
+
+// consistencyProofHex is the multiproof produced in TestMultiProofConsistency, which
+// matches the one produced by other implementations.
+const consistencyProofHex = "4f53588244efaf07a370ee3f9c467f933eed360d4fbf7a19dfc8bc49b67df4711bf1d0a720717cd6a8c75f1a668cb7cbdd63b48c676b89a7aee4298e71bd7f4013d7657146aa9736817da47051ed6a45fc7b5a61d00eb23e5df82a7f285cc10e67d444e91618465ca68d8ae4f2c916d1942201b7e2aae491ef0f809867d00e83468fb7f9af9b42ede76c1e90d89dd789ff22eb09e8b1d062d8a58b6f88b3cbe80136fc68331178cd45a1df9496ded092d976911b5244b85bc3de41e844ec194256b39aeee4ea55538a36139211e9910ad6b7a74e75d45b869d0a67aa4bf600930a5f760dfb8e4df9938d1f47b743d71c78ba8585e3b80aba26d24b1f50b36fa1458e79d54c05f58049245392bc3e2b5c5f9a1b99d43ed112ca82b201fb143d401741713188e47f1d6682b0bf496a5d4182836121efff0fd3b030fc6bfb5e21d6314a200963fe75cb856d444a813426b2084dfdc49dca2e649cb9da8bcb47859a4c629e97898e3547c591e39764110a224150d579c33fb74fa5eb96427036899c04154feab5344873d36a53a5baefd78c132be419f3f3a8dd8f60f72eb78dd5f43c53226f5ceb68947da3e19a750d760fb31fa8d4c7f53bfef11c4b89158aa56b1f4395430e16a3128f88e234ce1df7ef865f2d2c4975e8c82225f578310c31fd41d265fd530cbfa2b8895b228a510b806c31dff3b1fa5c08bffad443d567ed0e628febdd22775776e0cc9cebcaea9c6df9279a5d91dd0ee5e7a0434e989a160005321c97026cb559f71db23360105460d959bcdf74bee22c4ad8805a1d497507"
+
+func TestMultiProofBinaryEncoding(t *testing.T) {
+	golden, err := hex.DecodeString(consistencyProofHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var proof MultiProof
+	if err := proof.UnmarshalBinary(golden); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := proof.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, golden) {
+		t.Fatal("re-encoded proof doesn't match the golden encoding")
+	}
+
+	var buf bytes.Buffer
+	proof.Write(&buf)
+	if !bytes.Equal(buf.Bytes(), golden) {
+		t.Fatal("MarshalBinary and Write should produce the same encoding")
+	}
+
+	var got MultiProof
+	if err := got.UnmarshalBinary(append(append([]byte{}, golden...), 0)); err == nil {
+		t.Fatal("expected an error for trailing bytes")
+	}
+	if err := got.UnmarshalBinary(golden[:len(golden)-1]); err == nil {
+		t.Fatal("expected an error for truncated input")
+	}
+	invalidD := append([]byte{}, golden...)
+	for i := 0; i < 32; i++ {
+		invalidD[i] = 0xff
+	}
+	if err := got.UnmarshalBinary(invalidD); !errors.Is(err, banderwagon.ErrInvalidEncoding) {
+		t.Fatalf("expected an invalid encoding error for an invalid D, got %v", err)
+	}
+}
+
 func test_serialize_deserialize_proof(proof MultiProof) {
 	var buf = new(bytes.Buffer)
 	proof.Write(buf)